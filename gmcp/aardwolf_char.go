@@ -0,0 +1,136 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageAardwolfChar() Package {
+	return Package{
+		ID:      "char",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharBaseMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharVitalsMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharStatsMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharMaxStatsMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharStatusMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCharWorthMessage],
+			},
+		},
+	}
+}
+
+type AardwolfCharBaseMessage struct {
+	BaseMessage
+
+	Name     string  `json:"name"`
+	Class    string  `json:"class"`
+	Subclass string  `json:"subclass"`
+	Race     string  `json:"race"`
+	Clan     string  `json:"clan"`
+	Pretitle string  `json:"pretitle"`
+	PerLevel FlexInt `json:"perlevel"`
+	Tier     FlexInt `json:"tier"`
+	Remorts  FlexInt `json:"remorts"`
+	Redos    FlexInt `json:"redos"`
+}
+
+func (m AardwolfCharBaseMessage) ID() string {
+	return "char.base"
+}
+
+type AardwolfCharVitalsMessage struct {
+	BaseMessage
+
+	HP    FlexInt `json:"hp"`
+	Mana  FlexInt `json:"mana"`
+	Moves FlexInt `json:"moves"`
+}
+
+func (m AardwolfCharVitalsMessage) ID() string {
+	return "char.vitals"
+}
+
+type AardwolfCharStatsMessage struct {
+	BaseMessage
+
+	Str     FlexInt `json:"str"`
+	Int     FlexInt `json:"int"`
+	Wis     FlexInt `json:"wis"`
+	Dex     FlexInt `json:"dex"`
+	Con     FlexInt `json:"con"`
+	Luck    FlexInt `json:"luck"`
+	HitRoll FlexInt `json:"hr"`
+	DamRoll FlexInt `json:"dr"`
+	Saves   FlexInt `json:"saves"`
+}
+
+func (m AardwolfCharStatsMessage) ID() string {
+	return "char.stats"
+}
+
+type AardwolfCharMaxStatsMessage struct {
+	BaseMessage
+
+	MaxHP    FlexInt `json:"maxhp"`
+	MaxMana  FlexInt `json:"maxmana"`
+	MaxMoves FlexInt `json:"maxmoves"`
+	MaxStr   FlexInt `json:"maxstr"`
+	MaxInt   FlexInt `json:"maxint"`
+	MaxWis   FlexInt `json:"maxwis"`
+	MaxDex   FlexInt `json:"maxdex"`
+	MaxCon   FlexInt `json:"maxcon"`
+	MaxLuck  FlexInt `json:"maxluck"`
+}
+
+func (m AardwolfCharMaxStatsMessage) ID() string {
+	return "char.maxstats"
+}
+
+type AardwolfCharStatusMessage struct {
+	BaseMessage
+
+	Level        FlexInt `json:"level"`
+	TNL          FlexInt `json:"tnl"`
+	Hunger       FlexInt `json:"hunger"`
+	Thirst       FlexInt `json:"thirst"`
+	Align        FlexInt `json:"align"`
+	State        FlexInt `json:"state"`
+	Position     string  `json:"pos"`
+	Enemy        string  `json:"enemy"`
+	EnemyPercent FlexInt `json:"enemypct"`
+}
+
+func (m AardwolfCharStatusMessage) ID() string {
+	return "char.status"
+}
+
+type AardwolfCharWorthMessage struct {
+	BaseMessage
+
+	Gold         FlexInt `json:"gold"`
+	Bank         FlexInt `json:"bank"`
+	QuestPoints  FlexInt `json:"qp"`
+	TriviaPoints FlexInt `json:"tp"`
+	Trains       FlexInt `json:"trains"`
+	Practices    FlexInt `json:"pracs"`
+}
+
+func (m AardwolfCharWorthMessage) ID() string {
+	return "char.worth"
+}
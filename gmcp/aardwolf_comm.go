@@ -0,0 +1,82 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageAardwolfComm() Package {
+	return Package{
+		ID:      "comm",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCommChannelMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCommTickMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfCommQuestMessage],
+			},
+		},
+	}
+}
+
+type AardwolfCommChannelMessage struct {
+	BaseMessage
+
+	Channel string `json:"chan"`
+	Message string `json:"msg"`
+	Player  string `json:"player"`
+}
+
+func (m AardwolfCommChannelMessage) ID() string {
+	return "comm.channel"
+}
+
+type AardwolfCommTickMessage struct {
+	BaseMessage
+}
+
+func (m AardwolfCommTickMessage) ID() string {
+	return "comm.tick"
+}
+
+const (
+	QuestActionStart    = "start"
+	QuestActionKilled   = "killed"
+	QuestActionComplete = "comp"
+	QuestActionFail     = "fail"
+	QuestActionTimeout  = "timeout"
+	QuestActionWarning  = "warning"
+	QuestActionReset    = "reset"
+	QuestActionReady    = "ready"
+	QuestActionStatus   = "status"
+)
+
+// AardwolfCommQuestMessage carries every quest action. Which fields are present depends on
+// the action.
+type AardwolfCommQuestMessage struct {
+	BaseMessage
+
+	Action           string  `json:"action"`
+	Target           string  `json:"targ,omitempty"`
+	Room             string  `json:"room,omitempty"`
+	Area             string  `json:"area,omitempty"`
+	Timer            FlexInt `json:"timer,omitempty"`
+	Wait             FlexInt `json:"wait,omitempty"`
+	QuestPoints      FlexInt `json:"qp,omitempty"`
+	TriviaPoints     FlexInt `json:"tp,omitempty"`
+	Practices        FlexInt `json:"pracs,omitempty"`
+	Trains           FlexInt `json:"trains,omitempty"`
+	Gold             FlexInt `json:"gold,omitempty"`
+	TotalQuestPoints FlexInt `json:"totqp,omitempty"`
+	Completed        FlexInt `json:"comp,omitempty"`
+	Failed           FlexInt `json:"fail,omitempty"`
+	Status           string  `json:"status,omitempty"`
+}
+
+func (m AardwolfCommQuestMessage) ID() string {
+	return "comm.quest"
+}
@@ -0,0 +1,53 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageAardwolfGroup() Package {
+	return Package{
+		ID:      "group",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfGroupMessage],
+			},
+		},
+	}
+}
+
+type AardwolfGroupMemberInfo struct {
+	HP         FlexInt `json:"hp"`
+	MaxHP      FlexInt `json:"mhp"`
+	Mana       FlexInt `json:"mn"`
+	MaxMana    FlexInt `json:"mmn"`
+	Moves      FlexInt `json:"mv"`
+	MaxMoves   FlexInt `json:"mmv"`
+	Align      FlexInt `json:"align"`
+	TNL        FlexInt `json:"tnl"`
+	QuestTime  FlexInt `json:"qt"`
+	QuestState FlexInt `json:"qs"`
+	Level      FlexInt `json:"lvl"`
+	Here       FlexInt `json:"here"`
+}
+
+type AardwolfGroupMember struct {
+	Name string                  `json:"name"`
+	Info AardwolfGroupMemberInfo `json:"info"`
+}
+
+type AardwolfGroupMessage struct {
+	BaseMessage
+
+	GroupName string                `json:"groupname"`
+	Leader    string                `json:"leader"`
+	Created   string                `json:"created"`
+	Status    string                `json:"status"`
+	Count     FlexInt               `json:"count"`
+	Kills     FlexInt               `json:"kills"`
+	Exp       FlexInt               `json:"exp"`
+	Members   []AardwolfGroupMember `json:"members"`
+}
+
+func (m AardwolfGroupMessage) ID() string {
+	return "group"
+}
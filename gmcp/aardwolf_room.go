@@ -0,0 +1,39 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageAardwolfRoom() Package {
+	return Package{
+		ID:      "room",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[AardwolfRoomInfoMessage],
+			},
+		},
+	}
+}
+
+type AardwolfRoomCoord struct {
+	ID        FlexInt `json:"id"`
+	X         FlexInt `json:"x"`
+	Y         FlexInt `json:"y"`
+	Continent FlexInt `json:"cont"`
+}
+
+type AardwolfRoomInfoMessage struct {
+	BaseMessage
+
+	Number  int               `json:"num"`
+	Name    string            `json:"name"`
+	Zone    string            `json:"zone"`
+	Terrain string            `json:"terrain"`
+	Details string            `json:"details"`
+	Exits   map[string]int    `json:"exits"`
+	Coord   AardwolfRoomCoord `json:"coord"`
+}
+
+func (m AardwolfRoomInfoMessage) ID() string {
+	return "room.info"
+}
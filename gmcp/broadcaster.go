@@ -0,0 +1,86 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Broadcaster sends a single message to many GMCP connections, marshaling it only once.
+// Each connection still applies its own client support filter.
+type Broadcaster struct {
+	lock        sync.Mutex
+	connections map[*GMCP]struct{}
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		connections: make(map[*GMCP]struct{}),
+	}
+}
+
+func (b *Broadcaster) Add(g *GMCP) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.connections[g] = struct{}{}
+}
+
+func (b *Broadcaster) Remove(g *GMCP) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	delete(b.connections, g)
+}
+
+func (b *Broadcaster) Len() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return len(b.connections)
+}
+
+func (b *Broadcaster) Connections() []*GMCP {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	out := make([]*GMCP, 0, len(b.connections))
+	for g := range b.connections {
+		out = append(out, g)
+	}
+
+	return out
+}
+
+// Broadcast sends the message to every connection. Connections that have dropped are skipped,
+// and an error on one connection does not prevent delivery to the rest; all errors are joined
+// and returned.
+func (b *Broadcaster) Broadcast(message Message) error {
+	return b.BroadcastFunc(message, nil)
+}
+
+// BroadcastFunc sends the message to every connection for which filter returns true. A nil
+// filter sends to every connection.
+func (b *Broadcaster) BroadcastFunc(message Message, filter func(g *GMCP) bool) error {
+	rawJson, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	id := message.ID()
+
+	// Work from a copy so that connections can be added or removed mid-broadcast
+	var errs []error
+	for _, g := range b.Connections() {
+		if filter != nil && !filter(g) {
+			continue
+		}
+
+		err = g.SendRaw(id, rawJson)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,107 @@
+package gmcp
+
+import (
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestBroadcaster(t *testing.T) {
+	serverPackages := func() []Package {
+		return []Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()}
+	}
+
+	roomPair, _, roomServer := startGMCPPair(t, []Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()}, serverPackages())
+	charPair, _, charServer := startGMCPPair(t, []Package{NewPackageCore(), NewPackageChar()}, serverPackages())
+	droppedPair, _, droppedServer := startGMCPPair(t, []Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()}, serverPackages())
+
+	for _, connection := range []struct {
+		pair   *telnettest.Pair
+		server *GMCP
+	}{{roomPair, roomServer}, {charPair, charServer}, {droppedPair, droppedServer}} {
+		if !connection.pair.Server.Wait(func() bool { return connection.server.ClientSupports("Char") }) {
+			t.Fatal("server never saw Char support")
+		}
+	}
+
+	broadcaster := NewBroadcaster()
+	broadcaster.Add(roomServer)
+	broadcaster.Add(charServer)
+	broadcaster.Add(droppedServer)
+
+	if broadcaster.Len() != 3 {
+		t.Fatalf("expected 3 connections, got %d", broadcaster.Len())
+	}
+
+	// One connection drops while the broadcast is underway. The filter runs before each
+	// send, so the first call closes it before it can be reached.
+	var dropped bool
+	err := broadcaster.BroadcastFunc(RoomInfoMessage{Number: 12, Name: "A Test Room"}, func(g *GMCP) bool {
+		if !dropped {
+			dropped = true
+			droppedPair.Close()
+		}
+
+		return true
+	})
+	if err != nil {
+		t.Errorf("a dropped connection failed the broadcast: %v", err)
+	}
+
+	if _, ok := telnettest.WaitForEventType(roomPair.Client, func(room RoomInfoMessage) bool {
+		return room.Number == 12
+	}); !ok {
+		t.Fatal("a client supporting Room never received the broadcast")
+	}
+
+	// Messages on one connection arrive in order, so once Char.Name arrives, any Room.Info
+	// sent to the client that doesn't support Room would have arrived already
+	broadcaster.Remove(droppedServer)
+
+	err = broadcaster.Broadcast(CharNameMessage{CharName: CharName{Name: "Bob"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pair := range []*telnettest.Pair{roomPair, charPair} {
+		if _, ok := telnettest.WaitForEventType[CharNameMessage](pair.Client, nil); !ok {
+			t.Fatal("a client never received Char.Name")
+		}
+	}
+
+	for _, event := range charPair.Client.Events() {
+		if _, isRoom := event.(RoomInfoMessage); isRoom {
+			t.Error("Room.Info was sent to a client that doesn't support Room")
+		}
+	}
+
+	// Filters can limit a broadcast to some connections
+	roomPair.Client.ClearEvents()
+	charPair.Client.ClearEvents()
+
+	err = broadcaster.BroadcastFunc(CharNameMessage{CharName: CharName{Name: "Alice"}}, func(g *GMCP) bool {
+		return g == charServer
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := telnettest.WaitForEventType[CharNameMessage](charPair.Client, nil); !ok {
+		t.Fatal("the filtered connection never received Char.Name")
+	}
+
+	err = broadcaster.Broadcast(RoomWrongDirMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := telnettest.WaitForEventType[RoomWrongDirMessage](roomPair.Client, nil); !ok {
+		t.Fatal("Room.WrongDir never arrived")
+	}
+
+	for _, event := range roomPair.Client.Events() {
+		if name, isName := event.(CharNameMessage); isName {
+			t.Errorf("a connection excluded by the filter received %v", name)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package gmcp
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+const defaultChannelHistory = 500
+
+var ansiSequence = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+func StripANSI(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+
+	return ansiSequence.ReplaceAllString(s, "")
+}
+
+type ChannelEntry struct {
+	Time    time.Time
+	Channel string
+	Talker  string
+	Text    string
+	RawText string
+}
+
+type ChannelTextHook func(entry ChannelEntry)
+
+type ChannelManager struct {
+	lock        sync.Mutex
+	historySize int
+	channels    []CommChannel
+	history     map[string][]ChannelEntry
+	players     []CommPlayer
+
+	hookLock sync.Mutex
+	hooks    []ChannelTextHook
+}
+
+// NewChannelManager creates a ChannelManager that keeps historySize entries per channel.
+// If historySize is 0 or less, a default of 500 is used.
+func NewChannelManager(historySize int) *ChannelManager {
+	if historySize <= 0 {
+		historySize = defaultChannelHistory
+	}
+
+	return &ChannelManager{
+		historySize: historySize,
+		history:     make(map[string][]ChannelEntry),
+	}
+}
+
+func (m *ChannelManager) AddTextHook(hook ChannelTextHook) {
+	m.hookLock.Lock()
+	defer m.hookLock.Unlock()
+
+	m.hooks = append(m.hooks, hook)
+}
+
+// HandleEvent applies a Comm.Channel message event and returns true if the event was
+// a Comm.Channel message
+func (m *ChannelManager) HandleEvent(event telnet.TelOptEvent) bool {
+	switch msg := event.(type) {
+	case CommChannelListMessage:
+		m.lock.Lock()
+		m.channels = slices.Clone(msg.Value)
+		m.lock.Unlock()
+	case CommChannelPlayersServerMessage:
+		m.lock.Lock()
+		m.players = slices.Clone(msg.Value)
+		m.lock.Unlock()
+	case CommChannelTextMessage:
+		m.addText(msg)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (m *ChannelManager) addText(msg CommChannelTextMessage) {
+	entry := ChannelEntry{
+		Time:    time.Now(),
+		Channel: msg.Channel,
+		Talker:  msg.Talker,
+		Text:    StripANSI(msg.Text),
+		RawText: msg.Text,
+	}
+
+	m.lock.Lock()
+	history := append(m.history[msg.Channel], entry)
+	if len(history) > m.historySize {
+		history = slices.Delete(history, 0, len(history)-m.historySize)
+	}
+	m.history[msg.Channel] = history
+	m.lock.Unlock()
+
+	m.hookLock.Lock()
+	hooks := slices.Clone(m.hooks)
+	m.hookLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(entry)
+	}
+}
+
+func (m *ChannelManager) Channels() []CommChannel {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return slices.Clone(m.channels)
+}
+
+func (m *ChannelManager) Channel(name string) (CommChannel, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	index := slices.IndexFunc(m.channels, func(channel CommChannel) bool {
+		return strings.EqualFold(channel.Name, name)
+	})
+	if index < 0 {
+		return CommChannel{}, false
+	}
+
+	return m.channels[index], true
+}
+
+func (m *ChannelManager) History(channel string) []ChannelEntry {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return slices.Clone(m.history[channel])
+}
+
+func (m *ChannelManager) ClearHistory(channel string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.history, channel)
+}
+
+func (m *ChannelManager) Players() []CommPlayer {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return slices.Clone(m.players)
+}
+
+// ChannelPlayers returns the names of the players the server reported as listening to a channel
+func (m *ChannelManager) ChannelPlayers(channel string) []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var names []string
+	for _, player := range m.players {
+		if slices.ContainsFunc(player.Channels, func(c string) bool { return strings.EqualFold(c, channel) }) {
+			names = append(names, player.Name)
+		}
+	}
+
+	return names
+}
+
+// CommandLine builds the line that sends text to a channel, using the command the server
+// advertised for it in Comm.Channel.List
+func (m *ChannelManager) CommandLine(channel string, text string) (string, error) {
+	ch, exists := m.Channel(channel)
+	if !exists {
+		return "", fmt.Errorf("gmcp: unknown channel %q", channel)
+	}
+
+	if ch.Command == "" {
+		return "", fmt.Errorf("gmcp: channel %q has no command", channel)
+	}
+
+	return ch.Command + " " + text, nil
+}
+
+// SendToChannel sends the channel's command for text to the server as a line of input. Telnet
+// lines end with CR LF.
+func (m *ChannelManager) SendToChannel(terminal *telnet.Terminal, channel string, text string) error {
+	line, err := m.CommandLine(channel, text)
+	if err != nil {
+		return err
+	}
+
+	// Keyboard().WriteString would resend the previous command along with the line, since it
+	// doesn't reset the keyboard's decoder, so the text is queued directly
+	terminal.Keyboard().LineOut(terminal, telnet.TextData(line+"\r\n"))
+	return nil
+}
+
+func (m *ChannelManager) EnableChannel(g *GMCP, channel string) error {
+	msg := CommChannelEnableMessage{}
+	msg.Value = channel
+	return g.SendMessage(msg)
+}
+
+func (m *ChannelManager) RequestPlayers(g *GMCP) error {
+	return g.SendMessage(CommChannelPlayersClientMessage{})
+}
@@ -0,0 +1,41 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func TestSendToChannel(t *testing.T) {
+	pair := telnettest.Start(t, telnettest.PairConfig{})
+
+	manager := NewChannelManager(0)
+	list := CommChannelListMessage{}
+	list.Value = []CommChannel{{Name: "newbie", Caption: "Newbie", Command: "newbie"}}
+	manager.HandleEvent(list)
+
+	err := manager.SendToChannel(pair.Client.Terminal, "newbie", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = manager.SendToChannel(pair.Client.Terminal, "newbie", "again")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each line should end in CR LF, which the printer reports as control codes
+	expected := []telnet.TerminalData{
+		telnet.TextData("newbie hello"), telnet.ControlCodeData('\r'), telnet.ControlCodeData('\n'),
+		telnet.TextData("newbie again"), telnet.ControlCodeData('\r'), telnet.ControlCodeData('\n'),
+	}
+
+	ok := pair.Server.Wait(func() bool {
+		return slices.Equal(pair.Server.Output(), expected)
+	})
+	if !ok {
+		t.Errorf("server received %#v", pair.Server.Output())
+	}
+}
@@ -2,7 +2,6 @@ package gmcp
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/moodclient/telnet"
 )
@@ -68,6 +67,14 @@ func (m CharLoginMessage) ID() string {
 	return "Char.Login"
 }
 
+func (m CharLoginMessage) String() string {
+	return redactedLoginString(m.ID(), m.Name)
+}
+
+func (m CharLoginMessage) GoString() string {
+	return m.String()
+}
+
 type CharName struct {
 	Name     string `json:"name"`
 	FullName string `json:"fullname"`
@@ -93,9 +100,13 @@ func (m CharVitalsMessage) ID() string {
 	return "Char.Vitals"
 }
 
+func (m CharVitalsMessage) String() string {
+	return m.BaseMessage.String()
+}
+
 func (m *CharVitalsMessage) StringValue() string {
-	val, _ := m.MapMessage.Value("string")
-	return fmt.Sprintf("%+v", val)
+	val, _ := m.MapMessage.String("string")
+	return val
 }
 
 type CharStatusVarsMessage struct {
@@ -108,6 +119,10 @@ func (m CharStatusVarsMessage) ID() string {
 	return "Char.StatusVars"
 }
 
+func (m CharStatusVarsMessage) String() string {
+	return m.BaseMessage.String()
+}
+
 type CharStatusMessage struct {
 	BaseMessage
 
@@ -117,3 +132,7 @@ type CharStatusMessage struct {
 func (m CharStatusMessage) ID() string {
 	return "Char.Status"
 }
+
+func (m CharStatusMessage) String() string {
+	return m.BaseMessage.String()
+}
@@ -41,10 +41,11 @@ func (m CharAfflictionsListMessage) ID() string {
 	return "Char.Afflictions.List"
 }
 
+// CharAfflictionsAddMessage holds a single affliction, which is how IRE games send it
 type CharAfflictionsAddMessage struct {
 	BaseMessage
 
-	ValueMessage[[]Affliction]
+	Affliction
 }
 
 func (m CharAfflictionsAddMessage) ID() string {
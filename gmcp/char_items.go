@@ -182,7 +182,11 @@ func ParseItemAttributes(s string) ItemAttributes {
 	return att
 }
 
-func (i *ItemAttributes) MarshalJSON() ([]byte, error) {
+// MarshalJSON has a value receiver so that attributes are written as flag strings wherever
+// an Item appears. With a pointer receiver, encoding/json skipped it for items that weren't
+// addressable, such as the Item in a Char.Items.Add message passed to SendMessage by value,
+// and wrote the raw bit flags as a number instead.
+func (i ItemAttributes) MarshalJSON() ([]byte, error) {
 	return json.Marshal(i.String())
 }
 
@@ -205,6 +209,27 @@ type Item struct {
 	Attrib ItemAttributes `json:"attrib"`
 }
 
+// UnmarshalJSON accepts the item ID as either a number or a string. IRE games send it as a
+// string.
+func (i *Item) UnmarshalJSON(data []byte) error {
+	type itemFields Item
+
+	decoded := struct {
+		*itemFields
+		ID FlexInt `json:"id"`
+	}{
+		itemFields: (*itemFields)(i),
+	}
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	i.ID = int(decoded.ID)
+	return nil
+}
+
 type CharItemsListMessage struct {
 	BaseMessage
 
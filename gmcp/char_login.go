@@ -1,6 +1,12 @@
 package gmcp
 
-import "github.com/moodclient/telnet"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/moodclient/telnet"
+)
 
 func NewPackageCharLogin() Package {
 	return Package{
@@ -55,3 +61,58 @@ type CharLoginCredentialsMessage struct {
 func (m CharLoginCredentialsMessage) ID() string {
 	return "Char.Login.Credentials"
 }
+
+func (m CharLoginCredentialsMessage) String() string {
+	return redactedLoginString(m.ID(), m.Account)
+}
+
+func (m CharLoginCredentialsMessage) GoString() string {
+	return m.String()
+}
+
+// redactLoginPassword replaces the password in the raw body of a login message, so that it
+// can't leak through debug strings, unparsed messages or traffic hooks. Bodies of other
+// messages are returned unchanged.
+func redactLoginPassword(id string, raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || (!strings.EqualFold(id, "Char.Login") && !strings.EqualFold(id, "Char.Login.Credentials")) {
+		return raw
+	}
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(raw, &fields)
+	if err != nil {
+		// There's no telling where the password is in a malformed body, so none of it is safe
+		return json.RawMessage(`"REDACTED"`)
+	}
+
+	redacted := false
+	for key := range fields {
+		// encoding/json matches field names case-insensitively, so any of these is a password
+		if strings.EqualFold(key, "password") {
+			fields[key] = json.RawMessage(`"REDACTED"`)
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return raw
+	}
+
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return json.RawMessage(`"REDACTED"`)
+	}
+
+	return out
+}
+
+func redactedLoginString(id string, account string) string {
+	var sb strings.Builder
+	sb.WriteString("GMCP: ")
+	sb.WriteString(id)
+	sb.WriteString(" - ")
+	sb.WriteString(strconv.Quote(account))
+	sb.WriteString(" (password redacted)")
+
+	return sb.String()
+}
@@ -38,11 +38,16 @@ func (m CharSkillsGetMessage) ID() string {
 	return "Char.Skills.Get"
 }
 
+type SkillGroupRank struct {
+	Name string `json:"name"`
+	Rank string `json:"rank"`
+}
+
+// CharSkillsGroupsMessage lists every skill group the character has, along with their rank
 type CharSkillsGroupsMessage struct {
 	BaseMessage
 
-	Name string `json:"name"`
-	Rank string `json:"rank"`
+	ValueMessage[[]SkillGroupRank]
 }
 
 func (m CharSkillsGroupsMessage) ID() string {
@@ -0,0 +1,301 @@
+package gmcp
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+type CharacterChange int
+
+const (
+	CharacterChangeName CharacterChange = 1 << iota
+	CharacterChangeVitals
+	CharacterChangeStatus
+	CharacterChangeStatusVars
+	CharacterChangeAfflictions
+	CharacterChangeDefences
+	CharacterChangeSkills
+)
+
+type SkillGroup struct {
+	Name        string
+	Rank        string
+	Skills      []string
+	Description []string
+	Info        map[string]string
+}
+
+type CharacterSnapshot struct {
+	Name        CharName
+	Vitals      MapMessage
+	Status      MapMessage
+	StatusVars  MapMessage
+	Afflictions []Affliction
+	Defences    []Defence
+	SkillGroups map[string]SkillGroup
+}
+
+func (s CharacterSnapshot) HasAffliction(name string) bool {
+	return slices.ContainsFunc(s.Afflictions, func(a Affliction) bool {
+		return a.Name == name
+	})
+}
+
+func (s CharacterSnapshot) HasDefence(name string) bool {
+	return slices.ContainsFunc(s.Defences, func(d Defence) bool {
+		return d.Name == name
+	})
+}
+
+func (s CharacterSnapshot) clone() CharacterSnapshot {
+	out := CharacterSnapshot{
+		Name:        s.Name,
+		Vitals:      cloneMapMessage(s.Vitals),
+		Status:      cloneMapMessage(s.Status),
+		StatusVars:  cloneMapMessage(s.StatusVars),
+		Afflictions: slices.Clone(s.Afflictions),
+		Defences:    slices.Clone(s.Defences),
+		SkillGroups: make(map[string]SkillGroup, len(s.SkillGroups)),
+	}
+
+	for name, group := range s.SkillGroups {
+		group.Skills = slices.Clone(group.Skills)
+		group.Description = slices.Clone(group.Description)
+
+		info := make(map[string]string, len(group.Info))
+		for skill, text := range group.Info {
+			info[skill] = text
+		}
+		group.Info = info
+
+		out.SkillGroups[name] = group
+	}
+
+	return out
+}
+
+func cloneMapMessage(m MapMessage) MapMessage {
+	out := MapMessage{
+		ignoreKeys: m.ignoreKeys,
+		values:     make(map[string]any, len(m.values)),
+	}
+
+	for key, value := range m.values {
+		out.values[key] = value
+	}
+
+	return out
+}
+
+// mergeMapMessage copies the values from src into dst and returns true if any value changed.
+// Char.Status in particular is sent as a delta, so values are never removed.
+func mergeMapMessage(dst *MapMessage, src MapMessage) bool {
+	var changed bool
+
+	for key, value := range src.values {
+		oldValue, exists := dst.values[key]
+		if exists && reflect.DeepEqual(oldValue, value) {
+			continue
+		}
+
+		dst.SetValue(key, value)
+		changed = true
+	}
+
+	return changed
+}
+
+type CharacterChangeHook func(change CharacterChange, snapshot CharacterSnapshot)
+
+type CharacterState struct {
+	lock     sync.Mutex
+	snapshot CharacterSnapshot
+
+	hookLock sync.Mutex
+	hooks    []CharacterChangeHook
+}
+
+func NewCharacterState() *CharacterState {
+	return &CharacterState{
+		snapshot: CharacterSnapshot{
+			Vitals:      NewMapMessage("string"),
+			Status:      NewMapMessage(),
+			StatusVars:  NewMapMessage(),
+			SkillGroups: make(map[string]SkillGroup),
+		},
+	}
+}
+
+func (s *CharacterState) AddChangeHook(hook CharacterChangeHook) {
+	s.hookLock.Lock()
+	defer s.hookLock.Unlock()
+
+	s.hooks = append(s.hooks, hook)
+}
+
+func (s *CharacterState) Snapshot() CharacterSnapshot {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.snapshot.clone()
+}
+
+func (s *CharacterState) Reset() {
+	s.lock.Lock()
+	s.snapshot = CharacterSnapshot{
+		Vitals:      NewMapMessage("string"),
+		Status:      NewMapMessage(),
+		StatusVars:  NewMapMessage(),
+		SkillGroups: make(map[string]SkillGroup),
+	}
+	snapshot := s.snapshot.clone()
+	s.lock.Unlock()
+
+	s.raiseChange(CharacterChangeName|CharacterChangeVitals|CharacterChangeStatus|CharacterChangeStatusVars|
+		CharacterChangeAfflictions|CharacterChangeDefences|CharacterChangeSkills, snapshot)
+}
+
+// HandleEvent applies a GMCP message event to the character state, notifies change
+// hooks, and returns the set of changes that the event caused
+func (s *CharacterState) HandleEvent(event telnet.TelOptEvent) CharacterChange {
+	message, isMessage := event.(Message)
+	if !isMessage {
+		return 0
+	}
+
+	s.lock.Lock()
+	change := s.applyMessage(message)
+	var snapshot CharacterSnapshot
+	if change != 0 {
+		snapshot = s.snapshot.clone()
+	}
+	s.lock.Unlock()
+
+	if change != 0 {
+		s.raiseChange(change, snapshot)
+	}
+
+	return change
+}
+
+func (s *CharacterState) raiseChange(change CharacterChange, snapshot CharacterSnapshot) {
+	s.hookLock.Lock()
+	hooks := slices.Clone(s.hooks)
+	s.hookLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(change, snapshot)
+	}
+}
+
+func (s *CharacterState) applyMessage(message Message) CharacterChange {
+	switch msg := message.(type) {
+	case CharNameMessage:
+		if s.snapshot.Name == msg.CharName {
+			return 0
+		}
+
+		s.snapshot.Name = msg.CharName
+		return CharacterChangeName
+
+	case CharVitalsMessage:
+		if mergeMapMessage(&s.snapshot.Vitals, msg.MapMessage) {
+			return CharacterChangeVitals
+		}
+
+	case CharStatusMessage:
+		if mergeMapMessage(&s.snapshot.Status, msg.MapMessage) {
+			return CharacterChangeStatus
+		}
+
+	case CharStatusVarsMessage:
+		if mergeMapMessage(&s.snapshot.StatusVars, msg.MapMessage) {
+			return CharacterChangeStatusVars
+		}
+
+	case CharAfflictionsListMessage:
+		s.snapshot.Afflictions = slices.Clone(msg.Value)
+		return CharacterChangeAfflictions
+
+	case CharAfflictionsAddMessage:
+		s.snapshot.Afflictions = slices.DeleteFunc(s.snapshot.Afflictions, func(a Affliction) bool {
+			return a.Name == msg.Name
+		})
+		s.snapshot.Afflictions = append(s.snapshot.Afflictions, msg.Affliction)
+		return CharacterChangeAfflictions
+
+	case CharAfflictionsRemoveMessage:
+		oldLen := len(s.snapshot.Afflictions)
+		s.snapshot.Afflictions = slices.DeleteFunc(s.snapshot.Afflictions, func(a Affliction) bool {
+			return slices.Contains(msg.Value, a.Name)
+		})
+
+		if len(s.snapshot.Afflictions) != oldLen {
+			return CharacterChangeAfflictions
+		}
+
+	case CharDefencesListMessage:
+		s.snapshot.Defences = slices.Clone(msg.Value)
+		return CharacterChangeDefences
+
+	case CharDefencesAddMessage:
+		s.snapshot.Defences = slices.DeleteFunc(s.snapshot.Defences, func(d Defence) bool {
+			return d.Name == msg.Name
+		})
+		s.snapshot.Defences = append(s.snapshot.Defences, msg.Defence)
+		return CharacterChangeDefences
+
+	case CharDefencesRemoveMessage:
+		oldLen := len(s.snapshot.Defences)
+		s.snapshot.Defences = slices.DeleteFunc(s.snapshot.Defences, func(d Defence) bool {
+			return slices.Contains(msg.Value, d.Name)
+		})
+
+		if len(s.snapshot.Defences) != oldLen {
+			return CharacterChangeDefences
+		}
+
+	case CharSkillsGroupsMessage:
+		// The list is complete, so groups missing from it are no longer known to the character
+		groups := make(map[string]SkillGroup, len(msg.Value))
+		for _, rank := range msg.Value {
+			group := s.skillGroup(rank.Name)
+			group.Rank = rank.Rank
+			groups[rank.Name] = group
+		}
+
+		s.snapshot.SkillGroups = groups
+		return CharacterChangeSkills
+
+	case CharSkillsListMessage:
+		group := s.skillGroup(msg.Group)
+		group.Skills = slices.Clone(msg.List)
+		group.Description = slices.Clone(msg.Description)
+		s.snapshot.SkillGroups[msg.Group] = group
+		return CharacterChangeSkills
+
+	case CharSkillsInfoMessage:
+		group := s.skillGroup(msg.Group)
+		group.Info[msg.Skill] = msg.Info
+		s.snapshot.SkillGroups[msg.Group] = group
+		return CharacterChangeSkills
+	}
+
+	return 0
+}
+
+func (s *CharacterState) skillGroup(name string) SkillGroup {
+	group, exists := s.snapshot.SkillGroups[name]
+	if !exists {
+		group.Name = name
+	}
+
+	if group.Info == nil {
+		group.Info = make(map[string]string)
+	}
+
+	return group
+}
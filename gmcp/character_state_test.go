@@ -0,0 +1,134 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func wireMessage[T Message, U CreateMessageConstraint[T]](t *testing.T, body string) Message {
+	t.Helper()
+
+	msg, err := CreateMessage[T, U](nil, json.RawMessage(body))
+	if err != nil {
+		t.Fatalf("%s: %v", body, err)
+	}
+
+	return msg
+}
+
+func afflictionNames(snapshot CharacterSnapshot) []string {
+	var names []string
+	for _, affliction := range snapshot.Afflictions {
+		names = append(names, affliction.Name)
+	}
+
+	return names
+}
+
+func TestCharacterState(t *testing.T) {
+	state := NewCharacterState()
+
+	var hookChanges []CharacterChange
+	state.AddChangeHook(func(change CharacterChange, snapshot CharacterSnapshot) {
+		hookChanges = append(hookChanges, change)
+	})
+
+	steps := []struct {
+		name   string
+		msg    Message
+		change CharacterChange
+	}{
+		{"name", wireMessage[CharNameMessage](t, `{"name": "Bob", "fullname": "Bob the Brave"}`), CharacterChangeName},
+		{"same name", wireMessage[CharNameMessage](t, `{"name": "Bob", "fullname": "Bob the Brave"}`), 0},
+		{"vitals", wireMessage[CharVitalsMessage](t, `{"hp": "4500", "maxhp": "4800", "mp": "4000", "maxmp": "4000"}`), CharacterChangeVitals},
+		{"vitals delta", wireMessage[CharVitalsMessage](t, `{"hp": "4400"}`), CharacterChangeVitals},
+		{"unchanged vitals", wireMessage[CharVitalsMessage](t, `{"hp": "4400"}`), 0},
+		{"status", wireMessage[CharStatusMessage](t, `{"level": "58 (32%)", "city": "Ashtan (1)"}`), CharacterChangeStatus},
+		{"afflictions list", wireMessage[CharAfflictionsListMessage](t, `[{"name": "weariness", "cure": "eat kelp", "desc": "Reduces the damage you deal"}]`), CharacterChangeAfflictions},
+		// IRE sends a single affliction object, not a list
+		{"affliction add", wireMessage[CharAfflictionsAddMessage](t, `{"name": "clumsiness", "cure": "eat kelp", "desc": "You may miss attacks"}`), CharacterChangeAfflictions},
+		{"affliction remove", wireMessage[CharAfflictionsRemoveMessage](t, `["weariness"]`), CharacterChangeAfflictions},
+		{"unknown affliction remove", wireMessage[CharAfflictionsRemoveMessage](t, `["weariness"]`), 0},
+		{"defence add", wireMessage[CharDefencesAddMessage](t, `{"name": "insomnia", "desc": "insomnia"}`), CharacterChangeDefences},
+		{"skill groups", wireMessage[CharSkillsGroupsMessage](t, `[{"name": "survival", "rank": "Inept (2%)"}, {"name": "perception", "rank": "Transcendent (100%)"}]`), CharacterChangeSkills},
+		{"skills list", wireMessage[CharSkillsListMessage](t, `{"group": "survival", "list": ["Gash", "Vitality"]}`), CharacterChangeSkills},
+		{"skills info", wireMessage[CharSkillsInfoMessage](t, `{"group": "survival", "skill": "gash", "info": "Syntax: GASH <target>"}`), CharacterChangeSkills},
+		{"unrelated", wireMessage[RoomWrongDirMessage](t, `"ne"`), 0},
+	}
+
+	for _, step := range steps {
+		hookChanges = nil
+
+		if change := state.HandleEvent(step.msg); change != step.change {
+			t.Errorf("%s: expected change %d, got %d", step.name, step.change, change)
+		}
+
+		var expectedHooks []CharacterChange
+		if step.change != 0 {
+			expectedHooks = []CharacterChange{step.change}
+		}
+
+		if !slices.Equal(hookChanges, expectedHooks) {
+			t.Errorf("%s: expected hooks %v, got %v", step.name, expectedHooks, hookChanges)
+		}
+	}
+
+	snapshot := state.Snapshot()
+
+	if snapshot.Name.FullName != "Bob the Brave" {
+		t.Errorf("unexpected name %+v", snapshot.Name)
+	}
+
+	if hp, _ := snapshot.Vitals.Int("hp"); hp != 4400 {
+		t.Errorf("expected 4400 hp, got %d", hp)
+	}
+
+	if maxhp, _ := snapshot.Vitals.Int("maxhp"); maxhp != 4800 {
+		t.Errorf("vitals delta lost maxhp: %d", maxhp)
+	}
+
+	if names := afflictionNames(snapshot); !slices.Equal(names, []string{"clumsiness"}) {
+		t.Errorf("unexpected afflictions %v", names)
+	}
+
+	if !snapshot.HasAffliction("clumsiness") || !snapshot.HasDefence("insomnia") {
+		t.Error("snapshot is missing the added affliction or defence")
+	}
+
+	group := snapshot.SkillGroups["survival"]
+	if group.Rank != "Inept (2%)" || !slices.Equal(group.Skills, []string{"Gash", "Vitality"}) || group.Info["gash"] != "Syntax: GASH <target>" {
+		t.Errorf("unexpected skill group %+v", group)
+	}
+
+	if len(snapshot.SkillGroups) != 2 {
+		t.Errorf("expected 2 skill groups, got %v", snapshot.SkillGroups)
+	}
+
+	// Snapshots are copies, so changing one doesn't change the state
+	snapshot.Afflictions[0].Name = "changed"
+	snapshot.Vitals.SetValue("hp", 1)
+	snapshot.SkillGroups["survival"].Info["gash"] = "changed"
+
+	again := state.Snapshot()
+	if again.Afflictions[0].Name != "clumsiness" || again.SkillGroups["survival"].Info["gash"] != "Syntax: GASH <target>" {
+		t.Error("changing a snapshot changed the state")
+	}
+
+	if hp, _ := again.Vitals.Int("hp"); hp != 4400 {
+		t.Error("changing a snapshot's vitals changed the state")
+	}
+
+	// A new groups list drops groups that are no longer in it, but keeps what is known about
+	// the rest
+	state.HandleEvent(wireMessage[CharSkillsGroupsMessage](t, `[{"name": "survival", "rank": "Apprentice (10%)"}]`))
+	groups := state.Snapshot().SkillGroups
+	if len(groups) != 1 || groups["survival"].Rank != "Apprentice (10%)" || len(groups["survival"].Skills) != 2 {
+		t.Errorf("unexpected skill groups after a new list: %+v", groups)
+	}
+
+	state.Reset()
+	if reset := state.Snapshot(); reset.Name.Name != "" || len(reset.Afflictions) != 0 || reset.Vitals.Len() != 0 {
+		t.Errorf("reset left state behind: %+v", reset)
+	}
+}
@@ -11,10 +11,17 @@ func NewPackageClient() Package {
 				Sender: telnet.SideServer,
 				Create: CreateMessage[ClientMapMessage],
 			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[ClientGUIMessage],
+			},
 		},
 	}
 }
 
+// ClientMapMessage tells the client where to download a map file for the game. Mudlet's
+// Client.Map carries only this one URL: it is both where the map is downloaded from and the
+// map the client's mapper loads, so there is no separate mapping URL to send.
 type ClientMapMessage struct {
 	BaseMessage
 
@@ -24,3 +31,16 @@ type ClientMapMessage struct {
 func (m ClientMapMessage) ID() string {
 	return "Client.Map"
 }
+
+// ClientGUIMessage tells the client where to download the game's UI package. Clients
+// reinstall the package when the version is higher than the one they have.
+type ClientGUIMessage struct {
+	BaseMessage
+
+	Version FlexInt `json:"version"`
+	URL     string  `json:"url"`
+}
+
+func (m ClientGUIMessage) ID() string {
+	return "Client.GUI"
+}
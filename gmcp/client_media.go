@@ -1,6 +1,12 @@
 package gmcp
 
-import "github.com/moodclient/telnet"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/moodclient/telnet"
+)
 
 func NewPackageClientMedia() Package {
 	return Package{
@@ -27,6 +33,29 @@ func NewPackageClientMedia() Package {
 	}
 }
 
+const (
+	MediaTypeSound = "sound"
+	MediaTypeMusic = "music"
+	MediaTypeVideo = "video"
+)
+
+func validateMediaType(mediaType string) error {
+	switch mediaType {
+	case "", MediaTypeSound, MediaTypeMusic, MediaTypeVideo:
+		return nil
+	}
+
+	return fmt.Errorf("client.media: unknown media type %q", mediaType)
+}
+
+func validateMediaRange(name string, value int, min int, max int) error {
+	if value < min || value > max {
+		return fmt.Errorf("client.media: %s must be between %d and %d, got %d", name, min, max, value)
+	}
+
+	return nil
+}
+
 type ClientMediaDefaultMessage struct {
 	BaseMessage
 
@@ -48,6 +77,14 @@ func (m ClientMediaLoadMessage) ID() string {
 	return "Client.Media.Load"
 }
 
+func (m ClientMediaLoadMessage) Validate() error {
+	if m.Name == "" {
+		return errors.New("client.media.load: name is required")
+	}
+
+	return nil
+}
+
 type ClientMediaPlayMessage struct {
 	BaseMessage
 
@@ -70,6 +107,78 @@ func (m ClientMediaPlayMessage) ID() string {
 	return "Client.Media.Play"
 }
 
+// ContinuePlaying indicates whether music that is already playing should carry on rather
+// than restart. Clients treat a missing value as true, so a received message without a
+// continue key reports true even though its Continue field is false. Messages built locally
+// report Continue.
+func (m ClientMediaPlayMessage) ContinuePlaying() bool {
+	if len(m.RawMessage()) == 0 {
+		return m.Continue
+	}
+
+	var fields map[string]json.RawMessage
+	err := json.Unmarshal(m.RawMessage(), &fields)
+	if err != nil {
+		return m.Continue
+	}
+
+	if _, exists := fields["continue"]; !exists {
+		return true
+	}
+
+	return m.Continue
+}
+
+// Validate checks the message against the ranges that clients accept. Zero values are
+// not checked, since they are what callers that don't set a field send.
+func (m ClientMediaPlayMessage) Validate() error {
+	if m.Name == "" {
+		return errors.New("client.media.play: name is required")
+	}
+
+	err := validateMediaType(m.Type)
+	if err != nil {
+		return err
+	}
+
+	if m.Volume != 0 {
+		err = validateMediaRange("volume", m.Volume, 1, 100)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.Priority != 0 {
+		err = validateMediaRange("priority", m.Priority, 1, 100)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.FadeIn < 0 || m.FadeOut < 0 {
+		return errors.New("client.media.play: fades cannot be negative")
+	}
+
+	// start and finish are positions in milliseconds
+	if m.Start < 0 || m.Finish < 0 {
+		return errors.New("client.media.play: start and finish cannot be negative")
+	}
+
+	if m.Finish != 0 && m.Finish <= m.Start {
+		return fmt.Errorf("client.media.play: finish (%d) must be after start (%d)", m.Finish, m.Start)
+	}
+
+	if m.Loops != 0 && m.Loops < -1 {
+		return fmt.Errorf("client.media.play: loops must be -1 or positive, got %d", m.Loops)
+	}
+
+	if m.Continue && m.Type != MediaTypeMusic {
+		return errors.New("client.media.play: continue only applies to music")
+	}
+
+	return nil
+}
+
 type ClientMediaStopMessage struct {
 	BaseMessage
 
@@ -85,3 +194,23 @@ type ClientMediaStopMessage struct {
 func (m ClientMediaStopMessage) ID() string {
 	return "Client.Media.Stop"
 }
+
+func (m ClientMediaStopMessage) Validate() error {
+	err := validateMediaType(m.Type)
+	if err != nil {
+		return err
+	}
+
+	if m.Priority != 0 {
+		err = validateMediaRange("priority", m.Priority, 1, 100)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.FadeOut < 0 {
+		return errors.New("client.media.stop: fadeout cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package gmcp
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+// DeltaSender sends map-based messages such as Char.Vitals and Char.Status from the server,
+// including only the keys whose values differ from what the client last received. Values
+// are only treated as received once their message has actually been written, which for a
+// message coalesced by a rate limit is when it is flushed. Changes in a message that was
+// skipped, dropped or replaced while waiting are sent again next time.
+// The first message after creation or a resync is always sent in full.
+type DeltaSender struct {
+	session *ServerSession
+
+	lock     sync.Mutex
+	current  map[string]map[string]any
+	lastSent map[string]map[string]any
+	builders map[string]func(values MapMessage) Message
+}
+
+func NewDeltaSender(session *ServerSession) *DeltaSender {
+	return &DeltaSender{
+		session:  session,
+		current:  make(map[string]map[string]any),
+		lastSent: make(map[string]map[string]any),
+		builders: make(map[string]func(values MapMessage) Message),
+	}
+}
+
+// Resync forgets what the client has received and sends the full current state of every
+// message ID that has been sent so far
+func (d *DeltaSender) Resync() error {
+	d.lock.Lock()
+	clear(d.lastSent)
+	keys := slices.Sorted(maps.Keys(d.current))
+	d.lock.Unlock()
+
+	for _, key := range keys {
+		err := d.flush(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HandleEvent resyncs when the client changes the packages it supports
+func (d *DeltaSender) HandleEvent(event telnet.TelOptEvent) error {
+	switch event.(type) {
+	case CoreSupportsSetMessage, CoreSupportsAddMessage, CoreSupportsRemoveMessage:
+		return d.Resync()
+	}
+
+	return nil
+}
+
+func (d *DeltaSender) SendVitals(values map[string]any) error {
+	return d.send(CharVitalsMessage{}.ID(), values, func(values MapMessage) Message {
+		msg := CharVitalsMessage{MapMessage: NewMapMessage("string")}
+		mergeMapMessage(&msg.MapMessage, values)
+		return msg
+	})
+}
+
+func (d *DeltaSender) SendStatus(values map[string]any) error {
+	return d.send(CharStatusMessage{}.ID(), values, func(values MapMessage) Message {
+		return CharStatusMessage{MapMessage: values}
+	})
+}
+
+func (d *DeltaSender) SendStatusVars(values map[string]any) error {
+	return d.send(CharStatusVarsMessage{}.ID(), values, func(values MapMessage) Message {
+		return CharStatusVarsMessage{MapMessage: values}
+	})
+}
+
+// send merges values into the current state for id and sends whatever the client hasn't
+// received yet
+func (d *DeltaSender) send(id string, values map[string]any, build func(values MapMessage) Message) error {
+	key := strings.ToUpper(id)
+
+	d.lock.Lock()
+	current, exists := d.current[key]
+	if !exists {
+		current = make(map[string]any, len(values))
+		d.current[key] = current
+		d.builders[key] = build
+	}
+	maps.Copy(current, values)
+	d.lock.Unlock()
+
+	return d.flush(key)
+}
+
+// flush sends the values for key that differ from what the client last received, if any
+func (d *DeltaSender) flush(key string) error {
+	d.lock.Lock()
+	last, hasLast := d.lastSent[key]
+	changes := NewMapMessage()
+	for name, value := range d.current[key] {
+		oldValue, sent := last[name]
+		if !sent || !reflect.DeepEqual(oldValue, value) {
+			changes.SetValue(name, value)
+		}
+	}
+	build := d.builders[key]
+	d.lock.Unlock()
+
+	if hasLast && changes.Len() == 0 {
+		return nil
+	}
+
+	return d.session.sendTracked("Char", build(changes), func() {
+		d.commit(key, changes)
+	})
+}
+
+// commit records values as received by the client
+func (d *DeltaSender) commit(key string, changes MapMessage) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	last, hasLast := d.lastSent[key]
+	if !hasLast {
+		last = make(map[string]any, changes.Len())
+		d.lastSent[key] = last
+	}
+
+	maps.Copy(last, changes.values)
+}
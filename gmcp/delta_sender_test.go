@@ -0,0 +1,136 @@
+package gmcp
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestDeltaSender(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	delta := NewDeltaSender(NewServerSession(server))
+
+	expectVitals := func(step string, expected map[string]int) {
+		t.Helper()
+
+		vitals, ok := telnettest.WaitForEventType[CharVitalsMessage](pair.Client, nil)
+		if !ok {
+			t.Fatalf("%s: client never received Char.Vitals", step)
+		}
+		pair.Client.ClearEvents()
+
+		got := make(map[string]int)
+		for key := range vitals.Keys {
+			got[key], _ = vitals.Int(key)
+		}
+
+		if !maps.Equal(got, expected) {
+			t.Errorf("%s: expected %v, got %v", step, expected, got)
+		}
+	}
+
+	// Only one message gets through until the limit is removed
+	server.SetRateLimit("Char.Vitals", RateLimit{PerSecond: 0.001})
+
+	err := delta.SendVitals(map[string]any{"hp": 100, "mp": 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVitals("first send", map[string]int{"hp": 100, "mp": 50})
+
+	// This change is dropped by the rate limit, so it must not count as received
+	err = delta.SendVitals(map[string]any{"hp": 90})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetRateLimit("Char.Vitals", RateLimit{})
+
+	err = delta.SendVitals(map[string]any{"mp": 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVitals("after drop", map[string]int{"hp": 90, "mp": 40})
+
+	// Nothing has changed, so nothing is sent
+	err = delta.SendVitals(map[string]any{"mp": 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := server.SendStats("Char.Vitals"); stats.Sent != 2 {
+		t.Errorf("expected 2 vitals sent, got %+v", stats)
+	}
+
+	err = delta.Resync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectVitals("resync", map[string]int{"hp": 90, "mp": 40})
+}
+
+func TestDeltaSenderCoalesced(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	delta := NewDeltaSender(NewServerSession(server))
+
+	server.SetRateLimit("Char.Vitals", RateLimit{PerSecond: 0.001, Coalesce: true})
+
+	send := func(values map[string]any) {
+		t.Helper()
+
+		err := delta.SendVitals(values)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectVitals := func(step string, expected map[string]int) {
+		t.Helper()
+
+		vitals, ok := telnettest.WaitForEventType[CharVitalsMessage](pair.Client, nil)
+		if !ok {
+			t.Fatalf("%s: client never received Char.Vitals", step)
+		}
+		pair.Client.ClearEvents()
+
+		got := make(map[string]int)
+		for key := range vitals.Keys {
+			got[key], _ = vitals.Int(key)
+		}
+
+		if !maps.Equal(got, expected) {
+			t.Errorf("%s: expected %v, got %v", step, expected, got)
+		}
+	}
+
+	send(map[string]any{"hp": 100, "mp": 50})
+	expectVitals("first send", map[string]int{"hp": 100, "mp": 50})
+
+	// Both changes wait behind the limit, and the second message replaces the first
+	send(map[string]any{"hp": 90})
+	send(map[string]any{"mp": 40})
+
+	// Removing the limit flushes the waiting message, which counts as received once written
+	server.SetRateLimit("Char.Vitals", RateLimit{})
+	expectVitals("flush", map[string]int{"hp": 90, "mp": 40})
+
+	send(map[string]any{"hp": 80})
+	expectVitals("after flush", map[string]int{"hp": 80})
+}
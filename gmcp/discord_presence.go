@@ -0,0 +1,188 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+type DiscordPresence struct {
+	ApplicationID string
+	InviteURL     string
+
+	SmallImage     []string
+	SmallImageText string
+	Details        string
+	State          string
+	PartySize      int
+	PartyMax       int
+	Game           string
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+func (p DiscordPresence) clone() DiscordPresence {
+	p.SmallImage = slices.Clone(p.SmallImage)
+	return p
+}
+
+// PresenceSink receives the full presence state whenever it changes, such as a bridge to
+// the local Discord client
+type PresenceSink interface {
+	SetPresence(presence DiscordPresence) error
+}
+
+// ChannelPresenceSink is an in-process PresenceSink that delivers presence updates on a
+// channel. It stands in for the Discord IPC connection in tests.
+type ChannelPresenceSink struct {
+	updates chan DiscordPresence
+}
+
+// NewChannelPresenceSink creates a sink whose channel holds up to buffer updates. A buffer
+// of less than 1 is treated as 1.
+func NewChannelPresenceSink(buffer int) *ChannelPresenceSink {
+	return &ChannelPresenceSink{
+		updates: make(chan DiscordPresence, max(buffer, 1)),
+	}
+}
+
+// SetPresence never blocks. Each update holds the full presence, so when the buffer is full
+// the oldest update is discarded to make room for the new one.
+func (s *ChannelPresenceSink) SetPresence(presence DiscordPresence) error {
+	for {
+		select {
+		case s.updates <- presence:
+			return nil
+		default:
+		}
+
+		select {
+		case <-s.updates:
+		default:
+		}
+	}
+}
+
+func (s *ChannelPresenceSink) Updates() <-chan DiscordPresence {
+	return s.updates
+}
+
+// DiscordPresenceManager merges External.Discord messages into a full presence state on
+// the client side. External.Discord.Status only contains the fields that changed.
+type DiscordPresenceManager struct {
+	g    *GMCP
+	sink PresenceSink
+
+	lock     sync.Mutex
+	presence DiscordPresence
+}
+
+func NewDiscordPresenceManager(g *GMCP, sink PresenceSink) *DiscordPresenceManager {
+	return &DiscordPresenceManager{
+		g:    g,
+		sink: sink,
+	}
+}
+
+func (m *DiscordPresenceManager) Presence() DiscordPresence {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.presence.clone()
+}
+
+// Hello tells the server the client supports Discord presence, optionally sharing the user's
+// Discord name
+func (m *DiscordPresenceManager) Hello(user string, private bool) error {
+	return m.g.SendMessage(ExternalDiscordHelloMessage{
+		User:    user,
+		Private: private,
+	})
+}
+
+// RequestStatus asks the server to resend External.Discord.Info and External.Discord.Status
+func (m *DiscordPresenceManager) RequestStatus() error {
+	return m.g.SendMessage(ExternalDiscordGetMessage{})
+}
+
+// HandleEvent merges an External.Discord message event into the presence state and passes
+// the result to the sink
+func (m *DiscordPresenceManager) HandleEvent(event telnet.TelOptEvent) error {
+	m.lock.Lock()
+
+	switch msg := event.(type) {
+	case ExternalDiscordInfoMessage:
+		m.presence.ApplicationID = msg.ApplicationID
+		m.presence.InviteURL = msg.InviteURL
+	case ExternalDiscordStatusMessage:
+		m.mergeStatus(msg)
+	default:
+		m.lock.Unlock()
+		return nil
+	}
+
+	presence := m.presence.clone()
+	m.lock.Unlock()
+
+	if m.sink == nil {
+		return nil
+	}
+
+	return m.sink.SetPresence(presence)
+}
+
+func (m *DiscordPresenceManager) mergeStatus(msg ExternalDiscordStatusMessage) {
+	var fields map[string]json.RawMessage
+	if len(msg.RawMessage()) > 0 {
+		_ = json.Unmarshal(msg.RawMessage(), &fields)
+	}
+
+	// Messages built locally have no raw JSON, so treat every set field as present
+	present := func(key string, isSet bool) bool {
+		if fields == nil {
+			return isSet
+		}
+
+		_, exists := fields[key]
+		return exists
+	}
+
+	if present("smallimage", msg.SmallImage != nil) {
+		m.presence.SmallImage = slices.Clone(msg.SmallImage)
+	}
+
+	if present("smallimagetext", msg.SmallImageText != "") {
+		m.presence.SmallImageText = msg.SmallImageText
+	}
+
+	if present("details", msg.Details != "") {
+		m.presence.Details = msg.Details
+	}
+
+	if present("state", msg.State != "") {
+		m.presence.State = msg.State
+	}
+
+	if present("partysize", msg.PartySize != 0) {
+		m.presence.PartySize = msg.PartySize
+	}
+
+	if present("partymax", msg.PartyMax != 0) {
+		m.presence.PartyMax = msg.PartyMax
+	}
+
+	if present("game", msg.Game != "") {
+		m.presence.Game = msg.Game
+	}
+
+	if present("starttime", msg.StartTime != "") {
+		m.presence.StartTime = msg.Start()
+	}
+
+	if present("endtime", msg.EndTime != "") {
+		m.presence.EndTime = msg.End()
+	}
+}
@@ -0,0 +1,104 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestDiscordPresenceMerge(t *testing.T) {
+	sink := NewChannelPresenceSink(4)
+	manager := NewDiscordPresenceManager(nil, sink)
+
+	messages := []Message{
+		wireMessage[ExternalDiscordInfoMessage](t, `{"inviteurl": "https://discord.gg/abcdef", "applicationid": "1234567890"}`),
+		wireMessage[ExternalDiscordStatusMessage](t, `{"details": "Fighting a sewer rat", "state": "Level 5", "game": "Example MUD", "smallimage": ["server-icon"], "partysize": 2, "partymax": 6, "starttime": 1700000000}`),
+		// Only the changed fields, with the end time sent as a string
+		wireMessage[ExternalDiscordStatusMessage](t, `{"state": "Level 6", "endtime": "1700003600"}`),
+		// A key that is present clears the value even when it is empty
+		wireMessage[ExternalDiscordStatusMessage](t, `{"partysize": 0, "details": ""}`),
+	}
+
+	for _, msg := range messages {
+		err := manager.HandleEvent(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var updates []DiscordPresence
+	for len(updates) < len(messages) {
+		select {
+		case update := <-sink.Updates():
+			updates = append(updates, update)
+		case <-time.After(time.Second):
+			t.Fatalf("expected %d presence updates, got %d", len(messages), len(updates))
+		}
+	}
+
+	merged := updates[2]
+	if merged.Details != "Fighting a sewer rat" || merged.State != "Level 6" || merged.PartySize != 2 {
+		t.Errorf("partial status did not merge: %+v", merged)
+	}
+
+	if merged.ApplicationID != "1234567890" || !slices.Equal(merged.SmallImage, []string{"server-icon"}) {
+		t.Errorf("partial status lost earlier fields: %+v", merged)
+	}
+
+	if !merged.StartTime.Equal(time.Unix(1700000000, 0)) || !merged.EndTime.Equal(time.Unix(1700003600, 0)) {
+		t.Errorf("unexpected times %v %v", merged.StartTime, merged.EndTime)
+	}
+
+	cleared := manager.Presence()
+	if cleared.PartySize != 0 || cleared.Details != "" || cleared.PartyMax != 6 || cleared.State != "Level 6" {
+		t.Errorf("empty fields were not applied: %+v", cleared)
+	}
+}
+
+func TestChannelPresenceSinkKeepsLatest(t *testing.T) {
+	sink := NewChannelPresenceSink(1)
+
+	for _, state := range []string{"Level 5", "Level 6", "Level 7"} {
+		err := sink.SetPresence(DiscordPresence{State: state})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if update := <-sink.Updates(); update.State != "Level 7" {
+		t.Errorf("expected the latest presence, got %q", update.State)
+	}
+}
+
+func TestDiscordPresenceHelloAndGet(t *testing.T) {
+	pair, client, _ := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageExternalDiscord()},
+		[]Package{NewPackageCore(), NewPackageExternalDiscord()},
+	)
+
+	manager := NewDiscordPresenceManager(client, NewChannelPresenceSink(1))
+
+	err := manager.Hello("bob#1234", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hello, ok := telnettest.WaitForEventType[ExternalDiscordHelloMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("server never received External.Discord.Hello")
+	}
+	if hello.User != "bob#1234" || !hello.Private {
+		t.Errorf("unexpected hello %+v", hello)
+	}
+
+	err = manager.RequestStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := telnettest.WaitForEventType[ExternalDiscordGetMessage](pair.Server, nil); !ok {
+		t.Error("server never received External.Discord.Get")
+	}
+}
@@ -0,0 +1,282 @@
+package gmcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/moodclient/telnet"
+)
+
+type MessageDefinition struct {
+	// ID is the full message ID. IDs without a '.' are treated as relative to the package.
+	ID     string `json:"id"`
+	Sender string `json:"sender"`
+	// Schema describes the message body. When it is omitted, the body may be any JSON value.
+	Schema *JSONSchema `json:"schema,omitempty"`
+}
+
+type PackageDefinition struct {
+	ID       string              `json:"id"`
+	Version  int                 `json:"version"`
+	Messages []MessageDefinition `json:"messages"`
+}
+
+func parseSender(sender string) (telnet.TerminalSide, error) {
+	switch strings.ToLower(sender) {
+	case "client":
+		return telnet.SideClient, nil
+	case "server":
+		return telnet.SideServer, nil
+	}
+
+	return 0, fmt.Errorf("gmcp: unknown message sender %q", sender)
+}
+
+func (d PackageDefinition) Package() (Package, error) {
+	if d.ID == "" {
+		return Package{}, fmt.Errorf("gmcp: package definition has no id")
+	}
+
+	pkg := Package{
+		ID:      d.ID,
+		Version: d.Version,
+	}
+
+	if pkg.Version == 0 {
+		pkg.Version = 1
+	}
+
+	for _, message := range d.Messages {
+		sender, err := parseSender(message.Sender)
+		if err != nil {
+			return Package{}, err
+		}
+
+		id := message.ID
+		if !strings.Contains(id, ".") {
+			id = d.ID + "." + id
+		}
+
+		// Without a schema, nothing is known about the body, so any body is accepted
+		schema := message.Schema
+		if schema == nil {
+			schema = &JSONSchema{}
+		}
+
+		pkg.Messages = append(pkg.Messages, MessageData{
+			Sender: sender,
+			Create: dynamicMessageFactory(id, schema),
+			Schema: schema,
+		})
+	}
+
+	return pkg, nil
+}
+
+func dynamicMessageFactory(id string, schema *JSONSchema) MessageFactory {
+	return func(g *GMCP, raw json.RawMessage) (Message, error) {
+		msg := DynamicMessage{
+			id:         id,
+			schema:     schema,
+			MapMessage: NewMapMessage(),
+		}
+
+		err := InitializeMessage(g, raw, &msg)
+		return msg, err
+	}
+}
+
+func ParsePackageDefinitions(data []byte) ([]Package, error) {
+	var definitions []PackageDefinition
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		var definition PackageDefinition
+		err := json.Unmarshal(trimmed, &definition)
+		if err != nil {
+			return nil, err
+		}
+
+		definitions = append(definitions, definition)
+	} else {
+		err := json.Unmarshal(trimmed, &definitions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	packages := make([]Package, 0, len(definitions))
+	for _, definition := range definitions {
+		pkg, err := definition.Package()
+		if err != nil {
+			return nil, err
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	return packages, nil
+}
+
+// LoadPackageDefinitions reads either a single package definition or an array of package
+// definitions in JSON form
+func LoadPackageDefinitions(r io.Reader) ([]Package, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePackageDefinitions(data)
+}
+
+func LoadPackageDefinitionsFile(path string) ([]Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParsePackageDefinitions(data)
+}
+
+// DynamicMessage is a message from a package defined at runtime. Object bodies are available
+// through the embedded MapMessage, and other bodies through Body. Values are coerced to the
+// types declared in the message schema.
+type DynamicMessage struct {
+	BaseMessage
+
+	id     string
+	schema *JSONSchema
+	body   any
+	MapMessage
+}
+
+func NewDynamicMessage(id string) DynamicMessage {
+	return DynamicMessage{
+		id:         id,
+		MapMessage: NewMapMessage(),
+	}
+}
+
+func (m DynamicMessage) ID() string {
+	return m.id
+}
+
+func (m DynamicMessage) String() string {
+	return m.BaseMessage.String()
+}
+
+func (m DynamicMessage) Body() any {
+	if m.body != nil {
+		return m.body
+	}
+
+	return m.MapMessage.values
+}
+
+func (m *DynamicMessage) SetBody(body any) {
+	m.body = body
+}
+
+func (m DynamicMessage) MarshalJSON() ([]byte, error) {
+	if m.body != nil {
+		return json.Marshal(m.body)
+	}
+
+	return m.MapMessage.MarshalJSON()
+}
+
+func (m *DynamicMessage) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return err
+	}
+
+	if m.schema != nil {
+		value = coerceValue(m.schema, value)
+
+		err = m.schema.validateValue(m.id, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	obj, isObject := value.(map[string]any)
+	if !isObject {
+		m.body = value
+		return nil
+	}
+
+	for key, propValue := range obj {
+		m.SetValue(key, propValue)
+	}
+
+	return nil
+}
+
+// coerceValue converts values that servers commonly send as strings into the type
+// declared by the schema
+func coerceValue(schema *JSONSchema, value any) any {
+	if schema == nil {
+		return value
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		str, isString := value.(string)
+		if !isString {
+			return value
+		}
+
+		trimmed := strings.TrimSpace(str)
+		if _, err := strconv.ParseFloat(trimmed, 64); err != nil {
+			return value
+		}
+
+		return json.Number(trimmed)
+	case "boolean":
+		if _, isBool := value.(bool); isBool {
+			return value
+		}
+
+		b, ok := dynamicBool(value)
+		if !ok {
+			return value
+		}
+
+		return b
+	case "array":
+		items, isArray := value.([]any)
+		if !isArray {
+			return value
+		}
+
+		for i := range items {
+			items[i] = coerceValue(schema.Items, items[i])
+		}
+	case "object":
+		obj, isObject := value.(map[string]any)
+		if !isObject {
+			return value
+		}
+
+		for key, propValue := range obj {
+			propSchema, isProperty := schema.Properties[key]
+			if !isProperty {
+				propSchema = schema.AdditionalProperties
+			}
+
+			obj[key] = coerceValue(propSchema, propValue)
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,148 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/moodclient/telnet"
+)
+
+const questDefinitions = `[
+	{
+		"id": "Game.Quest",
+		"messages": [
+			{
+				"id": "Update",
+				"sender": "server",
+				"schema": {
+					"type": "object",
+					"properties": {
+						"id": {"type": "integer"},
+						"name": {"type": "string"},
+						"done": {"type": "boolean"},
+						"steps": {"type": "array", "items": {"type": "integer"}}
+					},
+					"required": ["id"]
+				}
+			},
+			{"id": "Game.Quest.Note", "sender": "Client"}
+		]
+	}
+]`
+
+func TestDynamicPackage(t *testing.T) {
+	packages, err := ParsePackageDefinitions([]byte(questDefinitions))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(packages) != 1 || packages[0].ID != "Game.Quest" || packages[0].Version != 1 || len(packages[0].Messages) != 2 {
+		t.Fatalf("unexpected packages: %+v", packages)
+	}
+
+	update, note := packages[0].Messages[0], packages[0].Messages[1]
+	if update.Sender != telnet.SideServer || note.Sender != telnet.SideClient {
+		t.Errorf("unexpected senders %v %v", update.Sender, note.Sender)
+	}
+
+	// Values sent as strings are coerced to the types in the schema
+	msg, err := update.Create(nil, json.RawMessage(`{"id": "7", "name": "Rats", "done": "yes", "steps": ["1", 2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quest := msg.(DynamicMessage)
+	if quest.ID() != "Game.Quest.Update" {
+		t.Errorf("relative ID was not expanded: %q", quest.ID())
+	}
+
+	if id, _ := quest.Value("id"); id != json.Number("7") {
+		t.Errorf("expected id to be coerced to a number, got %#v", id)
+	}
+
+	if done, _ := quest.Value("done"); done != true {
+		t.Errorf("expected done to be coerced to true, got %#v", done)
+	}
+
+	steps, _ := quest.Slice("steps")
+	if len(steps) != 2 || steps[0] != json.Number("1") || steps[1] != json.Number("2") {
+		t.Errorf("unexpected steps %#v", steps)
+	}
+
+	marshaled, err := json.Marshal(quest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"done":true,"id":7,"name":"Rats","steps":[1,2]}`; string(marshaled) != expected {
+		t.Errorf("expected %s, got %s", expected, marshaled)
+	}
+
+	invalid := []struct {
+		body  string
+		error string
+	}{
+		{`{"name": "Rats"}`, `missing required property "id"`},
+		{`{"id": "seven"}`, "expected integer"},
+		{`{"id": 1.5}`, "expected integer"},
+		{`{"id": 1, "name": 5}`, "expected string"},
+		{`{"id": 1, "steps": ["one"]}`, "expected integer"},
+		{`[1]`, "expected object"},
+	}
+
+	for _, test := range invalid {
+		_, err := update.Create(nil, json.RawMessage(test.body))
+		if err == nil || !strings.Contains(err.Error(), test.error) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.body, test.error, err)
+		}
+	}
+
+	// A message defined without a schema accepts any body
+	bodies := []struct {
+		body     string
+		expected string
+	}{
+		{`"remember the rats"`, `"remember the rats"`},
+		{`[1, "two"]`, `[1,"two"]`},
+		{`{"text": "rats", "count": 3}`, `{"count":3,"text":"rats"}`},
+		{`12`, `12`},
+	}
+
+	for _, test := range bodies {
+		msg, err := note.Create(nil, json.RawMessage(test.body))
+		if err != nil {
+			t.Errorf("%s: %v", test.body, err)
+			continue
+		}
+
+		marshaled, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(marshaled) != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, marshaled)
+		}
+	}
+}
+
+func TestPackageDefinitionErrors(t *testing.T) {
+	// A single definition doesn't need to be wrapped in an array
+	packages, err := ParsePackageDefinitions([]byte(`{"id": "Game", "version": 2, "messages": []}`))
+	if err != nil || len(packages) != 1 || packages[0].Version != 2 {
+		t.Errorf("single definition: %+v %v", packages, err)
+	}
+
+	invalid := []string{
+		`{"messages": []}`,
+		`{"id": "Game", "messages": [{"id": "Ping", "sender": "nobody"}]}`,
+		`[{"id": "Game"}`,
+	}
+
+	for _, definition := range invalid {
+		if _, err := ParsePackageDefinitions([]byte(definition)); err == nil {
+			t.Errorf("%s was accepted", definition)
+		}
+	}
+}
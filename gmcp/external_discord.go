@@ -1,6 +1,16 @@
 package gmcp
 
-import "github.com/moodclient/telnet"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
 
 func NewPackageExternalDiscord() Package {
 	return Package{
@@ -14,6 +24,7 @@ func NewPackageExternalDiscord() Package {
 			{
 				Sender: telnet.SideServer,
 				Create: CreateMessage[ExternalDiscordStatusMessage],
+				Schema: discordStatusSchema(),
 			},
 			{
 				Sender: telnet.SideClient,
@@ -38,6 +49,9 @@ func (m ExternalDiscordInfoMessage) ID() string {
 	return "External.Discord.Info"
 }
 
+// ExternalDiscordStatusMessage is sent with all of its fields, but servers may send only
+// the fields that changed. DiscordPresenceManager merges received messages by the keys they
+// contain.
 type ExternalDiscordStatusMessage struct {
 	BaseMessage
 
@@ -48,14 +62,120 @@ type ExternalDiscordStatusMessage struct {
 	PartySize      int      `json:"partysize"`
 	PartyMax       int      `json:"partymax"`
 	Game           string   `json:"game"`
-	StartTime      string   `json:"starttime,omitempty"`
-	EndTime        string   `json:"endtime,omitempty"`
+	// StartTime and EndTime are Unix timestamps. They are received as either JSON numbers
+	// or strings, and are sent as numbers when they hold an integer.
+	StartTime string `json:"starttime,omitempty"`
+	EndTime   string `json:"endtime,omitempty"`
 }
 
 func (m ExternalDiscordStatusMessage) ID() string {
 	return "External.Discord.Status"
 }
 
+// discordStatusFields has the fields of ExternalDiscordStatusMessage without its JSON methods
+type discordStatusFields ExternalDiscordStatusMessage
+
+// discordStatusSchema describes External.Discord.Status as it is sent, since the message
+// marshals itself. No field is required because servers send partial updates.
+func discordStatusSchema() *JSONSchema {
+	schema := SchemaForType(reflect.TypeOf(discordStatusFields{}))
+	schema.Required = nil
+	schema.Properties["starttime"] = &JSONSchema{Type: "integer", Description: "Unix timestamp"}
+	schema.Properties["endtime"] = &JSONSchema{Type: "integer", Description: "Unix timestamp"}
+
+	return schema
+}
+
+func (m ExternalDiscordStatusMessage) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		discordStatusFields
+		StartTime json.RawMessage `json:"starttime,omitempty"`
+		EndTime   json.RawMessage `json:"endtime,omitempty"`
+	}{
+		discordStatusFields: discordStatusFields(m),
+		StartTime:           marshalDiscordTime(m.StartTime),
+		EndTime:             marshalDiscordTime(m.EndTime),
+	})
+}
+
+func (m *ExternalDiscordStatusMessage) UnmarshalJSON(data []byte) error {
+	decoded := struct {
+		*discordStatusFields
+		StartTime json.RawMessage `json:"starttime"`
+		EndTime   json.RawMessage `json:"endtime"`
+	}{
+		discordStatusFields: (*discordStatusFields)(m),
+	}
+
+	err := json.Unmarshal(data, &decoded)
+	if err != nil {
+		return err
+	}
+
+	m.StartTime, err = unmarshalDiscordTime(decoded.StartTime)
+	if err != nil {
+		return err
+	}
+
+	m.EndTime, err = unmarshalDiscordTime(decoded.EndTime)
+	return err
+}
+
+func marshalDiscordTime(timestamp string) json.RawMessage {
+	if timestamp == "" {
+		return nil
+	}
+
+	if _, err := strconv.ParseInt(timestamp, 10, 64); err == nil {
+		return json.RawMessage(timestamp)
+	}
+
+	quoted, _ := json.Marshal(timestamp)
+	return quoted
+}
+
+func unmarshalDiscordTime(raw json.RawMessage) (string, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return "", nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return "", err
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case json.Number:
+		return typed.String(), nil
+	}
+
+	return "", fmt.Errorf("gmcp: cannot convert %s to a timestamp", string(trimmed))
+}
+
+func (m ExternalDiscordStatusMessage) Start() time.Time {
+	return discordTime(m.StartTime)
+}
+
+func (m ExternalDiscordStatusMessage) End() time.Time {
+	return discordTime(m.EndTime)
+}
+
+func discordTime(timestamp string) time.Time {
+	epoch, err := strconv.ParseFloat(strings.TrimSpace(timestamp), 64)
+	if err != nil || epoch == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(int64(epoch), 0)
+}
+
 type ExternalDiscordHelloMessage struct {
 	BaseMessage
 
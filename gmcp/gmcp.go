@@ -32,7 +32,7 @@ func (m BaseMessage) RawMessage() json.RawMessage {
 }
 
 func (m *BaseMessage) InitializeAsEvent(g *GMCP, message Message, raw json.RawMessage) {
-	m.rawMessage = raw
+	m.rawMessage = redactLoginPassword(message.ID(), raw)
 	m.idCache = message.ID()
 	m.BaseTelOptEvent = telopts.BaseTelOptEvent{TelnetOption: g}
 }
@@ -60,7 +60,12 @@ type MessageFactory func(g *GMCP, raw json.RawMessage) (Message, error)
 
 type MessageData struct {
 	Sender telnet.TerminalSide
+	// Create builds the message. Package.Schema calls it with a nil GMCP and no raw message
+	// to learn the message's ID and type, so it must tolerate both, as CreateMessage does.
 	Create MessageFactory
+
+	// Schema overrides the schema derived from the message's Go type
+	Schema *JSONSchema
 }
 
 type Package struct {
@@ -124,48 +129,33 @@ func (m *ValueMessage[T]) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &m.Value)
 }
 
-type MapMessage struct {
-	ignoreKeys map[string]struct{}
-	values     map[string]any
-}
-
-func NewMapMessage(ignoreKeys ...string) MapMessage {
-	msg := MapMessage{
-		ignoreKeys: make(map[string]struct{}),
-		values:     make(map[string]any),
-	}
-	for _, key := range ignoreKeys {
-		msg.ignoreKeys[key] = struct{}{}
-	}
-
-	return msg
-}
-
-func (m MapMessage) MarshalJSON() ([]byte, error) {
-	return json.Marshal(m.values)
-}
-
-func (m *MapMessage) UnmarshalJSON(data []byte) error {
-	return json.Unmarshal(data, m.values)
-}
+// FlexInt is an integer that can be unmarshaled from either a JSON number or a string,
+// since many servers send numbers as strings
+type FlexInt int
 
-func (m *MapMessage) Value(key string) (any, bool) {
-	value, exists := m.values[key]
-	return value, exists
+func (i FlexInt) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int(i))
 }
 
-func (m *MapMessage) SetValue(key string, value any) {
-	m.values[key] = value
-}
+func (i *FlexInt) UnmarshalJSON(data []byte) error {
+	var value any
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err
+	}
 
-func (m *MapMessage) Keys(yield func(string) bool) {
-	for key := range m.values {
-		_, ignored := m.ignoreKeys[key]
+	if value == nil {
+		*i = 0
+		return nil
+	}
 
-		if !ignored && !yield(key) {
-			return
-		}
+	converted, ok := dynamicInt(value)
+	if !ok {
+		return fmt.Errorf("gmcp: cannot convert %s to an integer", string(data))
 	}
+
+	*i = FlexInt(converted)
+	return nil
 }
 
 type UnknownMessage struct {
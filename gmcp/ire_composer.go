@@ -0,0 +1,41 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIREComposer() Package {
+	return Package{
+		ID:      "IRE.Composer",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREComposerEditMessage],
+			},
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IREComposerSetBufferMessage],
+			},
+		},
+	}
+}
+
+type IREComposerEditMessage struct {
+	BaseMessage
+
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+func (m IREComposerEditMessage) ID() string {
+	return "IRE.Composer.Edit"
+}
+
+type IREComposerSetBufferMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREComposerSetBufferMessage) ID() string {
+	return "IRE.Composer.SetBuffer"
+}
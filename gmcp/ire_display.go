@@ -0,0 +1,45 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIREDisplay() Package {
+	return Package{
+		ID:      "IRE.Display",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREDisplayFixedFontMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREDisplayOhmapMessage],
+			},
+		},
+	}
+}
+
+const (
+	DisplayStart = "start"
+	DisplayStop  = "stop"
+)
+
+type IREDisplayFixedFontMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREDisplayFixedFontMessage) ID() string {
+	return "IRE.Display.FixedFont"
+}
+
+type IREDisplayOhmapMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREDisplayOhmapMessage) ID() string {
+	return "IRE.Display.Ohmap"
+}
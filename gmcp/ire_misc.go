@@ -0,0 +1,92 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIREMisc() Package {
+	return Package{
+		ID:      "IRE.Misc",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IREMiscVotedMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREMiscRemindVoteMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREMiscAchievementMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREMiscURLMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IREMiscTipMessage],
+			},
+		},
+	}
+}
+
+type IREMiscVotedMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREMiscVotedMessage) ID() string {
+	return "IRE.Misc.Voted"
+}
+
+type IREMiscRemindVoteMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREMiscRemindVoteMessage) ID() string {
+	return "IRE.Misc.RemindVote"
+}
+
+type Achievement struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type IREMiscAchievementMessage struct {
+	BaseMessage
+
+	ValueMessage[[]Achievement]
+}
+
+func (m IREMiscAchievementMessage) ID() string {
+	return "IRE.Misc.Achievement"
+}
+
+type MiscURL struct {
+	URL    string `json:"url"`
+	Window string `json:"window"`
+}
+
+type IREMiscURLMessage struct {
+	BaseMessage
+
+	ValueMessage[[]MiscURL]
+}
+
+func (m IREMiscURLMessage) ID() string {
+	return "IRE.Misc.URL"
+}
+
+type IREMiscTipMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IREMiscTipMessage) ID() string {
+	return "IRE.Misc.Tip"
+}
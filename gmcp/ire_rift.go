@@ -0,0 +1,58 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIRERift() Package {
+	return Package{
+		ID:      "IRE.Rift",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IRERiftRequestMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRERiftListMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRERiftChangeMessage],
+			},
+		},
+	}
+}
+
+type RiftItem struct {
+	Name        string  `json:"name"`
+	Amount      FlexInt `json:"amount"`
+	Description string  `json:"desc"`
+}
+
+type IRERiftRequestMessage struct {
+	BaseMessage
+}
+
+func (m IRERiftRequestMessage) ID() string {
+	return "IRE.Rift.Request"
+}
+
+type IRERiftListMessage struct {
+	BaseMessage
+
+	ValueMessage[[]RiftItem]
+}
+
+func (m IRERiftListMessage) ID() string {
+	return "IRE.Rift.List"
+}
+
+type IRERiftChangeMessage struct {
+	BaseMessage
+
+	RiftItem
+}
+
+func (m IRERiftChangeMessage) ID() string {
+	return "IRE.Rift.Change"
+}
@@ -0,0 +1,68 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIRETarget() Package {
+	return Package{
+		ID:      "IRE.Target",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IRETargetSetClientMessage],
+			},
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IRETargetRequestMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRETargetSetServerMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRETargetInfoMessage],
+			},
+		},
+	}
+}
+
+type IRETargetSetClientMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IRETargetSetClientMessage) ID() string {
+	return "IRE.Target.Set"
+}
+
+type IRETargetRequestMessage struct {
+	BaseMessage
+}
+
+func (m IRETargetRequestMessage) ID() string {
+	return "IRE.Target.Request"
+}
+
+type IRETargetSetServerMessage struct {
+	BaseMessage
+
+	ValueMessage[string]
+}
+
+func (m IRETargetSetServerMessage) ID() string {
+	return "IRE.Target.Set"
+}
+
+type IRETargetInfoMessage struct {
+	BaseMessage
+
+	TargetID         string `json:"id"`
+	ShortDescription string `json:"short_desc"`
+	HealthPercent    string `json:"hpperc"`
+}
+
+func (m IRETargetInfoMessage) ID() string {
+	return "IRE.Target.Info"
+}
@@ -0,0 +1,109 @@
+package gmcp
+
+import "github.com/moodclient/telnet"
+
+func NewPackageIRETasks() Package {
+	return Package{
+		ID:      "IRE.Tasks",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IRETasksRequestMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRETasksListMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRETasksUpdateMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: CreateMessage[IRETasksCompletedMessage],
+			},
+		},
+	}
+}
+
+const (
+	TaskTypeTask        = "tasks"
+	TaskTypeQuest       = "quests"
+	TaskTypeAchievement = "achievements"
+)
+
+type Task struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"desc"`
+	Type        string `json:"type"`
+	Command     string `json:"cmd"`
+	Status      string `json:"status"`
+	Group       string `json:"group"`
+}
+
+func (t Task) Completed() bool {
+	return t.Status == "1"
+}
+
+func filterTasks(tasks []Task, taskType string) []Task {
+	var out []Task
+	for _, task := range tasks {
+		if task.Type == taskType {
+			out = append(out, task)
+		}
+	}
+
+	return out
+}
+
+type IRETasksRequestMessage struct {
+	BaseMessage
+}
+
+func (m IRETasksRequestMessage) ID() string {
+	return "IRE.Tasks.Request"
+}
+
+type IRETasksListMessage struct {
+	BaseMessage
+
+	ValueMessage[[]Task]
+}
+
+func (m IRETasksListMessage) ID() string {
+	return "IRE.Tasks.List"
+}
+
+func (m IRETasksListMessage) Tasks() []Task {
+	return filterTasks(m.Value, TaskTypeTask)
+}
+
+func (m IRETasksListMessage) Quests() []Task {
+	return filterTasks(m.Value, TaskTypeQuest)
+}
+
+func (m IRETasksListMessage) Achievements() []Task {
+	return filterTasks(m.Value, TaskTypeAchievement)
+}
+
+type IRETasksUpdateMessage struct {
+	BaseMessage
+
+	ValueMessage[[]Task]
+}
+
+func (m IRETasksUpdateMessage) ID() string {
+	return "IRE.Tasks.Update"
+}
+
+type IRETasksCompletedMessage struct {
+	BaseMessage
+
+	ValueMessage[[]Task]
+}
+
+func (m IRETasksCompletedMessage) ID() string {
+	return "IRE.Tasks.Completed"
+}
@@ -0,0 +1,100 @@
+package gmcp
+
+import (
+	"encoding/json"
+
+	"github.com/moodclient/telnet"
+)
+
+func NewPackageIRETime() Package {
+	return Package{
+		ID:      "IRE.Time",
+		Version: 1,
+		Messages: []MessageData{
+			{
+				Sender: telnet.SideClient,
+				Create: CreateMessage[IRETimeRequestMessage],
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: func(g *GMCP, raw json.RawMessage) (Message, error) {
+					m := IRETimeListMessage{
+						IRETime: IRETime{MapMessage: NewMapMessage()},
+					}
+
+					err := InitializeMessage(g, raw, &m)
+					return m, err
+				},
+			},
+			{
+				Sender: telnet.SideServer,
+				Create: func(g *GMCP, raw json.RawMessage) (Message, error) {
+					m := IRETimeUpdateMessage{
+						IRETime: IRETime{MapMessage: NewMapMessage()},
+					}
+
+					err := InitializeMessage(g, raw, &m)
+					return m, err
+				},
+			},
+		},
+	}
+}
+
+// IRETime holds the in-game date and time. IRE.Time.Update only contains the values that
+// changed, so values are optional.
+type IRETime struct {
+	MapMessage
+}
+
+func (t *IRETime) Day() (int, bool) {
+	return t.Int("day")
+}
+
+func (t *IRETime) MonthNumber() (int, bool) {
+	return t.Int("mon")
+}
+
+func (t *IRETime) MonthName() (string, bool) {
+	return t.MapMessage.String("month")
+}
+
+func (t *IRETime) Year() (int, bool) {
+	return t.Int("year")
+}
+
+func (t *IRETime) Hour() (int, bool) {
+	return t.Int("hour")
+}
+
+func (t *IRETime) DayNight() (int, bool) {
+	return t.Int("daynight")
+}
+
+type IRETimeRequestMessage struct {
+	BaseMessage
+}
+
+func (m IRETimeRequestMessage) ID() string {
+	return "IRE.Time.Request"
+}
+
+type IRETimeListMessage struct {
+	BaseMessage
+
+	IRETime
+}
+
+func (m IRETimeListMessage) ID() string {
+	return "IRE.Time.List"
+}
+
+type IRETimeUpdateMessage struct {
+	BaseMessage
+
+	IRETime
+}
+
+func (m IRETimeUpdateMessage) ID() string {
+	return "IRE.Time.Update"
+}
@@ -0,0 +1,221 @@
+package gmcp
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+const (
+	ItemLocationInventory = "inv"
+	ItemLocationRoom      = "room"
+)
+
+func ItemContainerLocation(containerID int) string {
+	return "rep" + strconv.Itoa(containerID)
+}
+
+func ParseItemContainerLocation(location string) (int, bool) {
+	idStr, isContainer := strings.CutPrefix(location, "rep")
+	if !isContainer {
+		return 0, false
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
+
+type ItemTracker struct {
+	lock      sync.Mutex
+	locations map[string][]Item
+}
+
+func NewItemTracker() *ItemTracker {
+	return &ItemTracker{
+		locations: make(map[string][]Item),
+	}
+}
+
+// RequestContents asks the server for the contents of a container. The contents will be tracked
+// under ItemContainerLocation(containerID) when the server responds.
+func (t *ItemTracker) RequestContents(g *GMCP, containerID int) error {
+	msg := CharItemsContentsMessage{}
+	msg.Value = containerID
+	return g.SendMessage(msg)
+}
+
+// RequestInventory asks the server for the full inventory listing
+func (t *ItemTracker) RequestInventory(g *GMCP) error {
+	return g.SendMessage(CharItemsInvMessage{})
+}
+
+// RequestRoom asks the server for the full room listing
+func (t *ItemTracker) RequestRoom(g *GMCP) error {
+	return g.SendMessage(CharItemsRoomMessage{})
+}
+
+// HandleEvent applies a Char.Items message event to the tracked locations and returns true
+// if the event was a Char.Items message
+func (t *ItemTracker) HandleEvent(event telnet.TelOptEvent) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch msg := event.(type) {
+	case CharItemsListMessage:
+		t.locations[msg.Location] = slices.Clone(msg.Items)
+	case CharItemsAddMessage:
+		t.removeItem(msg.Location, msg.Item.ID)
+		t.locations[msg.Location] = append(t.locations[msg.Location], msg.Item)
+	case CharItemsUpdateMessage:
+		items := t.locations[msg.Location]
+		index := slices.IndexFunc(items, func(item Item) bool {
+			return item.ID == msg.Item.ID
+		})
+
+		if index < 0 {
+			t.locations[msg.Location] = append(items, msg.Item)
+		} else {
+			items[index] = msg.Item
+		}
+	case CharItemsRemoveMessage:
+		t.removeItem(msg.Location, msg.Item.ID)
+
+		// Forget the contents of containers that are no longer anywhere we can see
+		if !t.itemVisible(msg.Item.ID) {
+			delete(t.locations, ItemContainerLocation(msg.Item.ID))
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (t *ItemTracker) removeItem(location string, id int) {
+	items, exists := t.locations[location]
+	if !exists {
+		return
+	}
+
+	t.locations[location] = slices.DeleteFunc(items, func(item Item) bool {
+		return item.ID == id
+	})
+}
+
+func (t *ItemTracker) itemVisible(id int) bool {
+	for _, items := range t.locations {
+		if slices.ContainsFunc(items, func(item Item) bool { return item.ID == id }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *ItemTracker) Reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	clear(t.locations)
+}
+
+func (t *ItemTracker) Locations() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	locations := make([]string, 0, len(t.locations))
+	for location := range t.locations {
+		locations = append(locations, location)
+	}
+	slices.Sort(locations)
+
+	return locations
+}
+
+func (t *ItemTracker) Items(location string) []Item {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return slices.Clone(t.locations[location])
+}
+
+func (t *ItemTracker) Inventory() []Item {
+	return t.Items(ItemLocationInventory)
+}
+
+func (t *ItemTracker) Room() []Item {
+	return t.Items(ItemLocationRoom)
+}
+
+func (t *ItemTracker) Contents(containerID int) []Item {
+	return t.Items(ItemContainerLocation(containerID))
+}
+
+func (t *ItemTracker) Item(location string, id int) (Item, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	index := slices.IndexFunc(t.locations[location], func(item Item) bool {
+		return item.ID == id
+	})
+	if index < 0 {
+		return Item{}, false
+	}
+
+	return t.locations[location][index], true
+}
+
+// FindAll returns the items in a location that have every one of the provided attributes
+func (t *ItemTracker) FindAll(location string, attrib ItemAttributes) []Item {
+	return t.find(location, func(item Item) bool {
+		return item.Attrib&attrib == attrib
+	})
+}
+
+// FindAny returns the items in a location that have at least one of the provided attributes
+func (t *ItemTracker) FindAny(location string, attrib ItemAttributes) []Item {
+	return t.find(location, func(item Item) bool {
+		return item.Attrib&attrib != 0
+	})
+}
+
+func (t *ItemTracker) find(location string, match func(Item) bool) []Item {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var out []Item
+	for _, item := range t.locations[location] {
+		if match(item) {
+			out = append(out, item)
+		}
+	}
+
+	return out
+}
+
+func (t *ItemTracker) Wielded() []Item {
+	return t.FindAny(ItemLocationInventory, ItemAttWieldedLeft|ItemAttWieldedRight)
+}
+
+func (t *ItemTracker) Worn() []Item {
+	return t.FindAll(ItemLocationInventory, ItemAttWorn)
+}
+
+func (t *ItemTracker) Containers(location string) []Item {
+	return t.FindAll(location, ItemAttContainer)
+}
+
+// Monsters returns the living monsters in the room that can be targeted
+func (t *ItemTracker) Monsters() []Item {
+	return t.find(ItemLocationRoom, func(item Item) bool {
+		return item.Attrib&ItemAttMonster != 0 &&
+			item.Attrib&(ItemAttDeadMonster|ItemAttNoTarget) == 0
+	})
+}
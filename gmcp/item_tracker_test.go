@@ -0,0 +1,86 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/moodclient/telnet"
+)
+
+func itemIDs(items []Item) []int {
+	var ids []int
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+func TestItemTracker(t *testing.T) {
+	tracker := NewItemTracker()
+
+	backpack := Item{ID: 100, Name: "a backpack", Attrib: ItemAttContainer | ItemAttWearableNotWorn}
+	sword := Item{ID: 101, Name: "a sword", Attrib: ItemAttWieldedLeft}
+	coin := Item{ID: 102, Name: "a coin"}
+	rat := Item{ID: 200, Name: "a rat", Attrib: ItemAttMonster}
+
+	events := []telnet.TelOptEvent{
+		CharItemsListMessage{Location: ItemLocationInventory, Items: []Item{backpack, sword}},
+		CharItemsListMessage{Location: ItemContainerLocation(100), Items: []Item{coin}},
+		CharItemsListMessage{Location: ItemLocationRoom, Items: []Item{rat}},
+		// Adding an item that is already present replaces it rather than duplicating it
+		CharItemsAddMessage{Location: ItemLocationInventory, Item: sword},
+		CharItemsUpdateMessage{Location: ItemLocationInventory, Item: Item{ID: 100, Name: "a backpack", Attrib: ItemAttContainer | ItemAttWorn}},
+	}
+
+	for _, event := range events {
+		if !tracker.HandleEvent(event) {
+			t.Fatalf("%T was not handled", event)
+		}
+	}
+
+	if tracker.HandleEvent(RoomWrongDirMessage{}) {
+		t.Error("Room.WrongDir was handled as an item event")
+	}
+
+	if ids := itemIDs(tracker.Inventory()); !slices.Equal(ids, []int{100, 101}) {
+		t.Errorf("unexpected inventory %v", ids)
+	}
+
+	if ids := itemIDs(tracker.Worn()); !slices.Equal(ids, []int{100}) {
+		t.Errorf("unexpected worn items %v", ids)
+	}
+
+	if ids := itemIDs(tracker.Wielded()); !slices.Equal(ids, []int{101}) {
+		t.Errorf("unexpected wielded items %v", ids)
+	}
+
+	if ids := itemIDs(tracker.Monsters()); !slices.Equal(ids, []int{200}) {
+		t.Errorf("unexpected monsters %v", ids)
+	}
+
+	// The rat dies and can no longer be targeted
+	tracker.HandleEvent(CharItemsUpdateMessage{Location: ItemLocationRoom, Item: Item{ID: 200, Name: "a rat", Attrib: ItemAttMonster | ItemAttDeadMonster}})
+	if ids := itemIDs(tracker.Monsters()); len(ids) != 0 {
+		t.Errorf("dead monster still listed: %v", ids)
+	}
+
+	// Moving the backpack keeps its contents, as long as it can still be seen somewhere
+	tracker.HandleEvent(CharItemsAddMessage{Location: ItemLocationRoom, Item: backpack})
+	tracker.HandleEvent(CharItemsRemoveMessage{Location: ItemLocationInventory, Item: backpack})
+
+	if ids := itemIDs(tracker.Contents(100)); !slices.Equal(ids, []int{102}) {
+		t.Errorf("contents lost while the backpack is visible: %v", ids)
+	}
+
+	// Once it is gone entirely, its contents are forgotten
+	tracker.HandleEvent(CharItemsRemoveMessage{Location: ItemLocationRoom, Item: backpack})
+
+	if locations := tracker.Locations(); !slices.Equal(locations, []string{ItemLocationInventory, ItemLocationRoom}) {
+		t.Errorf("unexpected locations %v", locations)
+	}
+
+	if _, ok := tracker.Item(ItemLocationInventory, 101); !ok {
+		t.Error("sword went missing")
+	}
+}
@@ -0,0 +1,170 @@
+package gmcp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+const LoginTypePasswordCredentials = "password-credentials"
+
+var ErrLoginUnsupported = errors.New("server does not offer password-credentials login")
+var ErrNoCredentialProvider = errors.New("login flow has no credential provider")
+var ErrCredentialsNotSent = errors.New("login credentials were not sent")
+
+type CredentialProvider interface {
+	Credentials(defaults CharLoginDefaultMessage) (account string, password string, err error)
+}
+
+type CredentialProviderFunc func(defaults CharLoginDefaultMessage) (string, string, error)
+
+func (f CredentialProviderFunc) Credentials(defaults CharLoginDefaultMessage) (string, string, error) {
+	return f(defaults)
+}
+
+// StaticCredentials returns a CredentialProvider that always supplies the same account and password
+func StaticCredentials(account string, password string) CredentialProvider {
+	return CredentialProviderFunc(func(CharLoginDefaultMessage) (string, string, error) {
+		return account, password, nil
+	})
+}
+
+type LoginState int
+
+const (
+	LoginWaiting LoginState = iota
+	LoginSubmitted
+	LoginSucceeded
+	LoginFailed
+	LoginUnsupported
+)
+
+func (s LoginState) String() string {
+	switch s {
+	case LoginWaiting:
+		return "Waiting"
+	case LoginSubmitted:
+		return "Submitted"
+	case LoginSucceeded:
+		return "Succeeded"
+	case LoginFailed:
+		return "Failed"
+	case LoginUnsupported:
+		return "Unsupported"
+	default:
+		return "Unknown"
+	}
+}
+
+type LoginResult struct {
+	State   LoginState
+	Message string
+	Err     error
+}
+
+type LoginResultHook func(result LoginResult)
+
+// LoginFlow logs in on the client side with Char.Login. It waits for Char.Login.Default,
+// submits credentials from the provider if the server offers password-credentials login,
+// and reports the outcome from Char.Login.Result.
+type LoginFlow struct {
+	g        *GMCP
+	provider CredentialProvider
+
+	lock   sync.Mutex
+	result LoginResult
+
+	hookLock sync.Mutex
+	hooks    []LoginResultHook
+}
+
+func NewLoginFlow(g *GMCP, provider CredentialProvider) *LoginFlow {
+	return &LoginFlow{
+		g:        g,
+		provider: provider,
+	}
+}
+
+func (f *LoginFlow) AddResultHook(hook LoginResultHook) {
+	f.hookLock.Lock()
+	defer f.hookLock.Unlock()
+
+	f.hooks = append(f.hooks, hook)
+}
+
+func (f *LoginFlow) State() LoginState {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.result.State
+}
+
+func (f *LoginFlow) Result() LoginResult {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.result
+}
+
+// HandleEvent advances the login flow from a Char.Login message event
+func (f *LoginFlow) HandleEvent(event telnet.TelOptEvent) {
+	switch msg := event.(type) {
+	case CharLoginDefaultMessage:
+		f.setResult(f.submit(msg))
+	case CharLoginResultMessage:
+		state := LoginFailed
+		if msg.Success {
+			state = LoginSucceeded
+		}
+
+		f.setResult(LoginResult{State: state, Message: msg.Message})
+	}
+}
+
+func (f *LoginFlow) submit(defaults CharLoginDefaultMessage) LoginResult {
+	if !slices.Contains(defaults.Type, LoginTypePasswordCredentials) {
+		return LoginResult{State: LoginUnsupported, Err: ErrLoginUnsupported}
+	}
+
+	if f.provider == nil {
+		return LoginResult{State: LoginFailed, Err: ErrNoCredentialProvider}
+	}
+
+	account, password, err := f.provider.Credentials(defaults)
+	if err != nil {
+		return LoginResult{State: LoginFailed, Err: err}
+	}
+
+	sendResult, err := f.g.TrySendMessage(CharLoginCredentialsMessage{
+		Account:  account,
+		Password: password,
+	})
+	if err != nil {
+		return LoginResult{State: LoginFailed, Err: err}
+	}
+
+	// Credentials that were skipped, dropped or left waiting behind a rate limit never
+	// reached the server, so there is no Char.Login.Result coming to finish the flow
+	if sendResult != SendResultSent {
+		return LoginResult{State: LoginFailed, Err: fmt.Errorf("%w: %s", ErrCredentialsNotSent, sendResult)}
+	}
+
+	return LoginResult{State: LoginSubmitted}
+}
+
+func (f *LoginFlow) setResult(result LoginResult) {
+	f.lock.Lock()
+	f.result = result
+	f.lock.Unlock()
+
+	f.hookLock.Lock()
+	hooks := slices.Clone(f.hooks)
+	f.hookLock.Unlock()
+
+	for _, hook := range hooks {
+		hook(result)
+	}
+}
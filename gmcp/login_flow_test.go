@@ -0,0 +1,131 @@
+package gmcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moodclient/mudopts"
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func TestLoginFlow(t *testing.T) {
+	pair, client, _ := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+	)
+
+	offered := CharLoginDefaultMessage{Type: []string{LoginTypePasswordCredentials}}
+
+	flow := NewLoginFlow(client, StaticCredentials("bob", "hunter2"))
+
+	var states []LoginState
+	flow.AddResultHook(func(result LoginResult) {
+		states = append(states, result.State)
+	})
+
+	if flow.State() != LoginWaiting {
+		t.Fatalf("expected a new flow to be waiting, got %s", flow.State())
+	}
+
+	flow.HandleEvent(offered)
+	if result := flow.Result(); result.State != LoginSubmitted || result.Err != nil {
+		t.Fatalf("expected the credentials to be submitted, got %s %v", result.State, result.Err)
+	}
+
+	credentials, ok := telnettest.WaitForEventType[CharLoginCredentialsMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("server never received Char.Login.Credentials")
+	}
+	if credentials.Account != "bob" || credentials.Password != "hunter2" {
+		t.Errorf("unexpected credentials %q %q", credentials.Account, credentials.Password)
+	}
+
+	flow.HandleEvent(CharLoginResultMessage{Success: false, Message: "Wrong password."})
+	if result := flow.Result(); result.State != LoginFailed || result.Message != "Wrong password." {
+		t.Errorf("expected a failed login, got %s %q", result.State, result.Message)
+	}
+
+	flow.HandleEvent(CharLoginResultMessage{Success: true})
+	if flow.State() != LoginSucceeded {
+		t.Errorf("expected a successful login, got %s", flow.State())
+	}
+
+	expected := []LoginState{LoginSubmitted, LoginFailed, LoginSucceeded}
+	if len(states) != len(expected) {
+		t.Fatalf("expected hook states %v, got %v", expected, states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Errorf("expected hook states %v, got %v", expected, states)
+			break
+		}
+	}
+
+	// A second submission inside the rate limit is dropped and never reaches the server
+	client.SetRateLimit("Char.Login.Credentials", RateLimit{PerSecond: 1.0 / 3600})
+	flow.HandleEvent(offered)
+	flow.HandleEvent(offered)
+
+	if result := flow.Result(); result.State != LoginFailed || !errors.Is(result.Err, ErrCredentialsNotSent) {
+		t.Errorf("expected dropped credentials to fail the login, got %s %v", result.State, result.Err)
+	}
+}
+
+func TestLoginFlowFailures(t *testing.T) {
+	offered := CharLoginDefaultMessage{Type: []string{LoginTypePasswordCredentials}}
+	providerErr := errors.New("no password saved")
+
+	// Not attached to a terminal, so every message is skipped
+	detached := RegisterGMCP(telnet.TelOptAllowRemote, mudopts.ClientInfo{}, NewPackageCore(), NewPackageCharLogin())
+
+	tests := []struct {
+		name     string
+		provider CredentialProvider
+		defaults CharLoginDefaultMessage
+		state    LoginState
+		err      error
+	}{
+		{
+			name:     "Unsupported",
+			provider: StaticCredentials("bob", "hunter2"),
+			defaults: CharLoginDefaultMessage{Type: []string{"oauth"}},
+			state:    LoginUnsupported,
+			err:      ErrLoginUnsupported,
+		},
+		{
+			name:     "NoProvider",
+			defaults: offered,
+			state:    LoginFailed,
+			err:      ErrNoCredentialProvider,
+		},
+		{
+			name: "ProviderError",
+			provider: CredentialProviderFunc(func(CharLoginDefaultMessage) (string, string, error) {
+				return "", "", providerErr
+			}),
+			defaults: offered,
+			state:    LoginFailed,
+			err:      providerErr,
+		},
+		{
+			name:     "Skipped",
+			provider: StaticCredentials("bob", "hunter2"),
+			defaults: offered,
+			state:    LoginFailed,
+			err:      ErrCredentialsNotSent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flow := NewLoginFlow(detached, test.provider)
+			flow.HandleEvent(test.defaults)
+
+			result := flow.Result()
+			if result.State != test.state || !errors.Is(result.Err, test.err) {
+				t.Errorf("expected %s %v, got %s %v", test.state, test.err, result.State, result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package gmcp
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+const (
+	defaultLoginMaxFailures = 3
+	defaultLoginLockout     = 30 * time.Second
+)
+
+var ErrNoAuthenticator = errors.New("login handler has no authenticator")
+
+type Authenticator interface {
+	// Authenticate checks an account and password. The returned message is sent to the client
+	// in Char.Login.Result. An error is treated as a failed login.
+	Authenticate(account string, password string) (success bool, message string, err error)
+}
+
+type AuthenticatorFunc func(account string, password string) (bool, string, error)
+
+func (f AuthenticatorFunc) Authenticate(account string, password string) (bool, string, error) {
+	return f(account, password)
+}
+
+type LoginHandlerConfig struct {
+	// Types are the login types offered in Char.Login.Default. Defaults to password-credentials.
+	Types    []string
+	Location string
+
+	// MaxFailures is the number of failed attempts allowed before further attempts are
+	// rejected for the Lockout duration. Defaults to 3 attempts and 30 seconds.
+	MaxFailures int
+	Lockout     time.Duration
+}
+
+type LoginHook func(account string)
+
+// LoginHandler authenticates Char.Login attempts on the server side for a single connection
+type LoginHandler struct {
+	g             *GMCP
+	authenticator Authenticator
+	config        LoginHandlerConfig
+	now           func() time.Time
+
+	// attemptLock serializes login attempts, so that the lockout check, authentication and
+	// the failure count they update act as one step. lock only guards the fields below, so
+	// that Account doesn't wait on a slow Authenticator.
+	attemptLock sync.Mutex
+
+	lock          sync.Mutex
+	sentDefault   bool
+	failures      int
+	lockedUntil   time.Time
+	authenticated string
+
+	hookLock sync.Mutex
+	hooks    []LoginHook
+}
+
+func NewLoginHandler(g *GMCP, authenticator Authenticator, config LoginHandlerConfig) *LoginHandler {
+	if len(config.Types) == 0 {
+		config.Types = []string{LoginTypePasswordCredentials}
+	}
+
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = defaultLoginMaxFailures
+	}
+
+	if config.Lockout <= 0 {
+		config.Lockout = defaultLoginLockout
+	}
+
+	return &LoginHandler{
+		g:             g,
+		authenticator: authenticator,
+		config:        config,
+		now:           time.Now,
+	}
+}
+
+func (h *LoginHandler) AddLoginHook(hook LoginHook) {
+	h.hookLock.Lock()
+	defer h.hookLock.Unlock()
+
+	h.hooks = append(h.hooks, hook)
+}
+
+// Account returns the account that successfully logged in, if any
+func (h *LoginHandler) Account() (string, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	return h.authenticated, h.authenticated != ""
+}
+
+// Start sends Char.Login.Default to the client. It is sent automatically once the client
+// advertises Char.Login support, so this only needs to be called to resend it.
+func (h *LoginHandler) Start() error {
+	h.lock.Lock()
+	h.sentDefault = true
+	h.lock.Unlock()
+
+	return h.g.SendMessage(CharLoginDefaultMessage{
+		Type:     slices.Clone(h.config.Types),
+		Location: h.config.Location,
+	})
+}
+
+// HandleEvent sends Char.Login.Default when the client advertises support, and authenticates
+// Char.Login.Credentials and Char.Login messages
+func (h *LoginHandler) HandleEvent(event telnet.TelOptEvent) error {
+	switch msg := event.(type) {
+	case CoreSupportsSetMessage, CoreSupportsAddMessage:
+		h.lock.Lock()
+		shouldSend := !h.sentDefault && h.authenticated == ""
+		h.lock.Unlock()
+
+		if shouldSend && h.g.ClientSupports("Char.Login") {
+			return h.Start()
+		}
+	case CharLoginCredentialsMessage:
+		return h.attempt(msg.Account, msg.Password)
+	case CharLoginMessage:
+		return h.attempt(msg.Name, msg.Password)
+	}
+
+	return nil
+}
+
+func (h *LoginHandler) attempt(account string, password string) error {
+	success, err := h.authenticate(account, password)
+
+	if success {
+		h.hookLock.Lock()
+		hooks := slices.Clone(h.hooks)
+		h.hookLock.Unlock()
+
+		for _, hook := range hooks {
+			hook(account)
+		}
+	}
+
+	return err
+}
+
+// authenticate checks the lockout, authenticates and records the outcome as a single step
+// for this session, then sends the result to the client
+func (h *LoginHandler) authenticate(account string, password string) (bool, error) {
+	h.attemptLock.Lock()
+	defer h.attemptLock.Unlock()
+
+	// Without an authenticator nobody can log in, but the client still gets an answer and the
+	// attempt doesn't count towards the lockout
+	if h.authenticator == nil {
+		return false, errors.Join(ErrNoAuthenticator, h.sendResult(false, "Login failed."))
+	}
+
+	h.lock.Lock()
+	now := h.now()
+	locked := now.Before(h.lockedUntil)
+	h.lock.Unlock()
+
+	if locked {
+		return false, h.sendResult(false, "Too many failed login attempts. Please wait and try again.")
+	}
+
+	success, message, err := h.authenticator.Authenticate(account, password)
+	if err != nil {
+		success = false
+		if message == "" {
+			message = "Login failed."
+		}
+	}
+
+	h.lock.Lock()
+	if success {
+		h.failures = 0
+		h.authenticated = account
+	} else {
+		h.failures++
+		if h.failures >= h.config.MaxFailures {
+			h.failures = 0
+			h.lockedUntil = now.Add(h.config.Lockout)
+		}
+	}
+	h.lock.Unlock()
+
+	resultErr := h.sendResult(success, message)
+	if err != nil {
+		return success, err
+	}
+
+	return success, resultErr
+}
+
+func (h *LoginHandler) sendResult(success bool, message string) error {
+	return h.g.SendMessage(CharLoginResultMessage{
+		Success: success,
+		Message: message,
+	})
+}
@@ -0,0 +1,145 @@
+package gmcp
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestLoginHandlerLockout(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char.Login") }) {
+		t.Fatal("server never saw Char.Login support")
+	}
+
+	var calls atomic.Int32
+	handler := NewLoginHandler(server, AuthenticatorFunc(func(account string, password string) (bool, string, error) {
+		calls.Add(1)
+		if password != "hunter2" {
+			return false, "Wrong password.", nil
+		}
+		return true, "Welcome.", nil
+	}), LoginHandlerConfig{MaxFailures: 2, Lockout: time.Minute})
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	handler.now = func() time.Time { return now }
+
+	var loggedIn []string
+	handler.AddLoginHook(func(account string) {
+		loggedIn = append(loggedIn, account)
+	})
+
+	attempt := func(password string) {
+		t.Helper()
+
+		err := handler.HandleEvent(CharLoginCredentialsMessage{Account: "bob", Password: password})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	attempt("wrong")
+	attempt("wrong")
+
+	// Locked out, so even the right password is rejected without asking the authenticator
+	attempt("hunter2")
+
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 authentications, got %d", calls.Load())
+	}
+
+	if _, authenticated := handler.Account(); authenticated || len(loggedIn) > 0 {
+		t.Fatal("login succeeded while locked out")
+	}
+
+	now = now.Add(time.Minute)
+	attempt("hunter2")
+
+	if account, _ := handler.Account(); account != "bob" || len(loggedIn) != 1 {
+		t.Errorf("expected bob to be logged in, got %q %v", account, loggedIn)
+	}
+
+	if _, ok := telnettest.WaitForEventType(pair.Client, func(result CharLoginResultMessage) bool {
+		return result.Success
+	}); !ok {
+		t.Error("client never received a successful Char.Login.Result")
+	}
+}
+
+func TestLoginHandlerSerializesAttempts(t *testing.T) {
+	_, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+	)
+
+	var inFlight, maxInFlight, calls atomic.Int32
+	handler := NewLoginHandler(server, AuthenticatorFunc(func(account string, password string) (bool, string, error) {
+		calls.Add(1)
+
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		if current > maxInFlight.Load() {
+			maxInFlight.Store(current)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		return false, "Wrong password.", nil
+	}), LoginHandlerConfig{MaxFailures: 3, Lockout: time.Minute})
+
+	var wait sync.WaitGroup
+	for range 10 {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			_ = handler.HandleEvent(CharLoginCredentialsMessage{Account: "bob", Password: "wrong"})
+		}()
+	}
+	wait.Wait()
+
+	if maxInFlight.Load() != 1 {
+		t.Errorf("expected one authentication at a time, saw %d", maxInFlight.Load())
+	}
+
+	// Attempts that waited behind the third failure must see the lockout
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 authentications before the lockout, got %d", calls.Load())
+	}
+}
+
+func TestLoginHandlerWithoutAuthenticator(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+		[]Package{NewPackageCore(), NewPackageCharLogin()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char.Login") }) {
+		t.Fatal("server never saw Char.Login support")
+	}
+
+	handler := NewLoginHandler(server, nil, LoginHandlerConfig{})
+
+	err := handler.HandleEvent(CharLoginCredentialsMessage{Account: "bob", Password: "hunter2"})
+	if !errors.Is(err, ErrNoAuthenticator) {
+		t.Errorf("expected ErrNoAuthenticator, got %v", err)
+	}
+
+	if _, authenticated := handler.Account(); authenticated {
+		t.Error("login succeeded without an authenticator")
+	}
+
+	if _, ok := telnettest.WaitForEventType(pair.Client, func(result CharLoginResultMessage) bool {
+		return !result.Success
+	}); !ok {
+		t.Error("client never received a failed Char.Login.Result")
+	}
+}
@@ -0,0 +1,282 @@
+package gmcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type MapMessage struct {
+	ignoreKeys map[string]struct{}
+	values     map[string]any
+}
+
+func NewMapMessage(ignoreKeys ...string) MapMessage {
+	msg := MapMessage{
+		ignoreKeys: make(map[string]struct{}),
+		values:     make(map[string]any),
+	}
+	for _, key := range ignoreKeys {
+		msg.ignoreKeys[key] = struct{}{}
+	}
+
+	return msg
+}
+
+func (m MapMessage) MarshalJSON() ([]byte, error) {
+	if m.values == nil {
+		return []byte("{}"), nil
+	}
+
+	return json.Marshal(m.values)
+}
+
+func (m *MapMessage) UnmarshalJSON(data []byte) error {
+	// Decode numbers as json.Number so that integers aren't mangled by a trip through float64
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var values map[string]any
+	err := decoder.Decode(&values)
+	if err != nil {
+		return err
+	}
+
+	_, err = decoder.Token()
+	if !errors.Is(err, io.EOF) {
+		return errors.New("gmcp: unexpected data after JSON object")
+	}
+
+	// Unmarshaling replaces the contents rather than merging into them
+	if values == nil {
+		values = make(map[string]any)
+	}
+	m.values = values
+
+	return nil
+}
+
+func (m *MapMessage) Value(key string) (any, bool) {
+	value, exists := m.values[key]
+	return value, exists
+}
+
+func (m *MapMessage) SetValue(key string, value any) {
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+
+	m.values[key] = value
+}
+
+func (m *MapMessage) DeleteValue(key string) {
+	delete(m.values, key)
+}
+
+func (m *MapMessage) Has(key string) bool {
+	_, exists := m.values[key]
+	return exists
+}
+
+func (m *MapMessage) Len() int {
+	return len(m.values)
+}
+
+func (m *MapMessage) Keys(yield func(string) bool) {
+	for key := range m.values {
+		_, ignored := m.ignoreKeys[key]
+
+		if !ignored && !yield(key) {
+			return
+		}
+	}
+}
+
+func (m *MapMessage) Number(key string) (json.Number, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return "", false
+	}
+
+	return dynamicNumber(value)
+}
+
+func (m *MapMessage) Int(key string) (int, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return 0, false
+	}
+
+	return dynamicInt(value)
+}
+
+func (m *MapMessage) Float(key string) (float64, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return 0, false
+	}
+
+	return dynamicFloat(value)
+}
+
+// String returns the value at key as a string. Numbers and booleans are formatted. Types
+// that embed both BaseMessage and MapMessage must define String() themselves, since the two
+// embedded String methods hide each other.
+func (m *MapMessage) String(key string) (string, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return "", false
+	}
+
+	return dynamicString(value)
+}
+
+func (m *MapMessage) Bool(key string) (bool, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return false, false
+	}
+
+	return dynamicBool(value)
+}
+
+func (m *MapMessage) Map(key string) (MapMessage, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return MapMessage{}, false
+	}
+
+	switch typed := value.(type) {
+	case map[string]any:
+		return MapMessage{values: typed}, true
+	case MapMessage:
+		return typed, true
+	case *MapMessage:
+		if typed == nil {
+			return MapMessage{}, false
+		}
+		return *typed, true
+	}
+
+	return MapMessage{}, false
+}
+
+func (m *MapMessage) Slice(key string) ([]any, bool) {
+	value, exists := m.values[key]
+	if !exists {
+		return nil, false
+	}
+
+	slice, isSlice := value.([]any)
+	return slice, isSlice
+}
+
+func dynamicNumber(value any) (json.Number, bool) {
+	switch typed := value.(type) {
+	case json.Number:
+		return typed, true
+	case string:
+		// Many servers send numbers as strings
+		trimmed := strings.TrimSpace(typed)
+		_, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil {
+			return "", false
+		}
+		return json.Number(trimmed), true
+	case int:
+		return json.Number(strconv.Itoa(typed)), true
+	case int64:
+		return json.Number(strconv.FormatInt(typed, 10)), true
+	case float64:
+		return json.Number(strconv.FormatFloat(typed, 'f', -1, 64)), true
+	}
+
+	return "", false
+}
+
+func dynamicInt(value any) (int, bool) {
+	switch typed := value.(type) {
+	case int:
+		return typed, true
+	case int64:
+		return int(typed), true
+	case float64:
+		return int(typed), true
+	}
+
+	number, ok := dynamicNumber(value)
+	if !ok {
+		return 0, false
+	}
+
+	i, err := number.Int64()
+	if err == nil {
+		return int(i), true
+	}
+
+	// Some servers send fractional values for integer stats
+	f, err := number.Float64()
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+
+	return int(f), true
+}
+
+func dynamicFloat(value any) (float64, bool) {
+	number, ok := dynamicNumber(value)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := number.Float64()
+	if err != nil {
+		return 0, false
+	}
+
+	return f, true
+}
+
+func dynamicString(value any) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case json.Number:
+		return typed.String(), true
+	case bool:
+		return strconv.FormatBool(typed), true
+	case nil:
+		return "", false
+	case map[string]any, []any:
+		return "", false
+	}
+
+	return fmt.Sprintf("%v", value), true
+}
+
+func dynamicBool(value any) (bool, bool) {
+	switch typed := value.(type) {
+	case bool:
+		return typed, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(typed)) {
+		case "1", "true", "yes", "on":
+			return true, true
+		case "0", "false", "no", "off", "":
+			return false, true
+		}
+		return false, false
+	}
+
+	f, ok := dynamicFloat(value)
+	if !ok {
+		return false, false
+	}
+
+	return f != 0, true
+}
@@ -0,0 +1,91 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMapMessageUnmarshal(t *testing.T) {
+	// The zero value has a nil map, which used to be passed straight to json.Unmarshal
+	var msg MapMessage
+	err := json.Unmarshal([]byte(`{"hp": "4500", "maxhp": 4800, "big": 9007199254740993, "ratio": "0.25", "pk": "yes", "name": 42, "stats": {"str": "18"}, "list": [1, 2]}`), &msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hp, ok := msg.Int("hp"); !ok || hp != 4500 {
+		t.Errorf("expected hp sent as a string to read as 4500, got %d %t", hp, ok)
+	}
+
+	if maxhp, ok := msg.Int("maxhp"); !ok || maxhp != 4800 {
+		t.Errorf("expected maxhp 4800, got %d %t", maxhp, ok)
+	}
+
+	// Numbers are kept as json.Number, so large integers aren't rounded through float64
+	if big, _ := msg.Number("big"); big != "9007199254740993" {
+		t.Errorf("large integer was not preserved: %s", big)
+	}
+
+	if ratio, ok := msg.Float("ratio"); !ok || ratio != 0.25 {
+		t.Errorf("expected ratio 0.25, got %v %t", ratio, ok)
+	}
+
+	if pk, ok := msg.Bool("pk"); !ok || !pk {
+		t.Errorf("expected pk to read as true, got %t %t", pk, ok)
+	}
+
+	if name, ok := msg.String("name"); !ok || name != "42" {
+		t.Errorf("expected name to read as \"42\", got %q %t", name, ok)
+	}
+
+	stats, ok := msg.Map("stats")
+	if !ok {
+		t.Fatal("nested map was not readable")
+	}
+
+	if str, _ := stats.Int("str"); str != 18 {
+		t.Errorf("expected nested str 18, got %d", str)
+	}
+
+	if list, _ := msg.Slice("list"); len(list) != 2 {
+		t.Errorf("unexpected list %v", list)
+	}
+
+	if _, ok := msg.Int("name-missing"); ok {
+		t.Error("missing key was reported as present")
+	}
+
+	if _, ok := msg.Int("stats"); ok {
+		t.Error("a map was read as an int")
+	}
+
+	// Unmarshaling again replaces the contents rather than merging into them
+	err = json.Unmarshal([]byte(`{"mp": 10}`), &msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Has("hp") || msg.Len() != 1 {
+		t.Errorf("unmarshal merged into old values: %d keys", msg.Len())
+	}
+
+	if err := json.Unmarshal([]byte(`{"mp": 10} {"hp": 1}`), &msg); err == nil {
+		t.Error("trailing JSON was accepted")
+	}
+}
+
+func TestCharVitalsStringNumbers(t *testing.T) {
+	msg, err := CreateMessage[CharVitalsMessage](nil, []byte(`{"hp": "4500", "maxhp": "4800", "string": "H:4500/4800"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vitals := msg.(CharVitalsMessage)
+	if hp, _ := vitals.Int("hp"); hp != 4500 {
+		t.Errorf("expected 4500 hp, got %d", hp)
+	}
+
+	if vitals.StringValue() != "H:4500/4800" {
+		t.Errorf("unexpected string %q", vitals.StringValue())
+	}
+}
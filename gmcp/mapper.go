@@ -0,0 +1,307 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+const mapperFileVersion = 1
+
+type MapRoom struct {
+	Number      int            `json:"num"`
+	Name        string         `json:"name"`
+	Area        string         `json:"area"`
+	Environment string         `json:"environment,omitempty"`
+	Coords      string         `json:"coords,omitempty"`
+	Map         string         `json:"map,omitempty"`
+	Details     []string       `json:"details,omitempty"`
+	Exits       map[string]int `json:"exits"`
+}
+
+func (r MapRoom) clone() MapRoom {
+	r.Details = slices.Clone(r.Details)
+
+	exits := make(map[string]int, len(r.Exits))
+	for dir, dest := range r.Exits {
+		exits[dir] = dest
+	}
+	r.Exits = exits
+
+	return r
+}
+
+func (r MapRoom) ParsedCoords() (RoomCoords, bool) {
+	return ParseRoomCoords(r.Coords)
+}
+
+func (r MapRoom) DetailFlags() RoomDetails {
+	return ParseRoomDetails(r.Details)
+}
+
+type mapperFile struct {
+	Version int       `json:"version"`
+	Rooms   []MapRoom `json:"rooms"`
+}
+
+type Mapper struct {
+	lock        sync.Mutex
+	rooms       map[int]MapRoom
+	currentRoom int
+	hasCurrent  bool
+}
+
+func NewMapper() *Mapper {
+	return &Mapper{
+		rooms: make(map[int]MapRoom),
+	}
+}
+
+// HandleEvent records the room from a Room.Info message event and returns true if the
+// event was a Room.Info message
+func (m *Mapper) HandleEvent(event telnet.TelOptEvent) bool {
+	msg, isRoomInfo := event.(RoomInfoMessage)
+	if !isRoomInfo {
+		return false
+	}
+
+	m.AddRoom(MapRoom{
+		Number:      msg.Number,
+		Name:        msg.Name,
+		Area:        msg.Area,
+		Environment: msg.Environment,
+		Coords:      msg.Coords,
+		Map:         msg.Map,
+		Details:     msg.Details,
+		Exits:       msg.Exits,
+	})
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.currentRoom = msg.Number
+	m.hasCurrent = true
+
+	return true
+}
+
+func (m *Mapper) AddRoom(room MapRoom) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.rooms[room.Number] = room.clone()
+}
+
+func (m *Mapper) RemoveRoom(number int) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.rooms, number)
+}
+
+func (m *Mapper) Room(number int) (MapRoom, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	room, exists := m.rooms[number]
+	if !exists {
+		return MapRoom{}, false
+	}
+
+	return room.clone(), true
+}
+
+func (m *Mapper) CurrentRoom() (MapRoom, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if !m.hasCurrent {
+		return MapRoom{}, false
+	}
+
+	room, exists := m.rooms[m.currentRoom]
+	if !exists {
+		return MapRoom{}, false
+	}
+
+	return room.clone(), true
+}
+
+func (m *Mapper) RoomCount() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return len(m.rooms)
+}
+
+func (m *Mapper) Areas() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	areaSet := make(map[string]struct{})
+	for _, room := range m.rooms {
+		areaSet[room.Area] = struct{}{}
+	}
+
+	areas := make([]string, 0, len(areaSet))
+	for area := range areaSet {
+		areas = append(areas, area)
+	}
+	slices.Sort(areas)
+
+	return areas
+}
+
+func (m *Mapper) AreaRooms(area string) []MapRoom {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var rooms []MapRoom
+	for _, room := range m.rooms {
+		if room.Area == area {
+			rooms = append(rooms, room.clone())
+		}
+	}
+
+	slices.SortFunc(rooms, func(a, b MapRoom) int {
+		return a.Number - b.Number
+	})
+
+	return rooms
+}
+
+// Path returns the shortest list of exit directions leading from one room to another, or
+// false if no path is known
+func (m *Mapper) Path(from int, to int) ([]string, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if from == to {
+		return []string{}, true
+	}
+
+	type step struct {
+		previous  int
+		direction string
+	}
+
+	visited := map[int]step{from: {}}
+	queue := []int{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		room, exists := m.rooms[current]
+		if !exists {
+			continue
+		}
+
+		// Walk exits in a stable order so that equal-length paths are chosen consistently
+		directions := make([]string, 0, len(room.Exits))
+		for direction := range room.Exits {
+			directions = append(directions, direction)
+		}
+		slices.Sort(directions)
+
+		for _, direction := range directions {
+			dest := room.Exits[direction]
+			if _, seen := visited[dest]; seen {
+				continue
+			}
+
+			visited[dest] = step{previous: current, direction: direction}
+
+			if dest == to {
+				var path []string
+				for node := to; node != from; node = visited[node].previous {
+					path = append(path, visited[node].direction)
+				}
+				slices.Reverse(path)
+
+				return path, true
+			}
+
+			queue = append(queue, dest)
+		}
+	}
+
+	return nil, false
+}
+
+func (m *Mapper) Save(w io.Writer) error {
+	m.lock.Lock()
+	file := mapperFile{
+		Version: mapperFileVersion,
+		Rooms:   make([]MapRoom, 0, len(m.rooms)),
+	}
+
+	for _, room := range m.rooms {
+		file.Rooms = append(file.Rooms, room)
+	}
+	m.lock.Unlock()
+
+	slices.SortFunc(file.Rooms, func(a, b MapRoom) int {
+		return a.Number - b.Number
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(file)
+}
+
+// Load merges the rooms from a previously-saved map into the mapper
+func (m *Mapper) Load(r io.Reader) error {
+	var file mapperFile
+	err := json.NewDecoder(r).Decode(&file)
+	if err != nil {
+		return err
+	}
+
+	if file.Version != mapperFileVersion {
+		return fmt.Errorf("mapper: unsupported map file version %d", file.Version)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, room := range file.Rooms {
+		if room.Exits == nil {
+			room.Exits = make(map[string]int)
+		}
+
+		m.rooms[room.Number] = room
+	}
+
+	return nil
+}
+
+func (m *Mapper) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = m.Save(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (m *Mapper) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return m.Load(file)
+}
@@ -0,0 +1,44 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMapperPath(t *testing.T) {
+	mapper := NewMapper()
+
+	// 1 -n-> 2 -e-> 3 and 1 -e-> 4 -n-> 3 are equally short, 5 is a dead end and 6 is
+	// only reachable through a room that hasn't been mapped
+	mapper.AddRoom(MapRoom{Number: 1, Exits: map[string]int{"n": 2, "e": 4, "w": 5}})
+	mapper.AddRoom(MapRoom{Number: 2, Exits: map[string]int{"e": 3, "s": 1}})
+	mapper.AddRoom(MapRoom{Number: 3, Exits: map[string]int{"d": 7}})
+	mapper.AddRoom(MapRoom{Number: 4, Exits: map[string]int{"n": 3, "w": 1}})
+	mapper.AddRoom(MapRoom{Number: 5})
+	mapper.AddRoom(MapRoom{Number: 6})
+
+	tests := []struct {
+		from     int
+		to       int
+		path     []string
+		hasRoute bool
+	}{
+		{1, 1, []string{}, true},
+		{1, 2, []string{"n"}, true},
+		// Exits are walked in sorted order, so e is preferred over n
+		{1, 3, []string{"e", "n"}, true},
+		{2, 4, []string{"s", "e"}, true},
+		{3, 1, nil, false},
+		{1, 6, nil, false},
+		{5, 1, nil, false},
+		// The destination only needs to be known as an exit
+		{1, 7, []string{"e", "n", "d"}, true},
+	}
+
+	for _, test := range tests {
+		path, hasRoute := mapper.Path(test.from, test.to)
+		if hasRoute != test.hasRoute || !slices.Equal(path, test.path) {
+			t.Errorf("%d to %d: expected %v %t, got %v %t", test.from, test.to, test.path, test.hasRoute, path, hasRoute)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package gmcp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+type MediaCacheEntry struct {
+	Name     string
+	URL      string
+	LoadedAt time.Time
+}
+
+// MediaCache tracks the media the server has asked the client to preload with
+// Client.Media.Load, along with the default URL used to resolve media without their own URL
+type MediaCache struct {
+	lock       sync.Mutex
+	defaultURL string
+	entries    map[string]MediaCacheEntry
+}
+
+func NewMediaCache() *MediaCache {
+	return &MediaCache{
+		entries: make(map[string]MediaCacheEntry),
+	}
+}
+
+// HandleEvent applies a Client.Media message event and returns true if the event was
+// a Client.Media.Default or Client.Media.Load message
+func (c *MediaCache) HandleEvent(event telnet.TelOptEvent) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch msg := event.(type) {
+	case ClientMediaDefaultMessage:
+		c.defaultURL = msg.URL
+	case ClientMediaLoadMessage:
+		if msg.Validate() != nil {
+			return false
+		}
+
+		url := msg.URL
+		if url == "" {
+			url = c.defaultURL
+		}
+
+		c.entries[msg.Name] = MediaCacheEntry{
+			Name:     msg.Name,
+			URL:      url,
+			LoadedAt: time.Now(),
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (c *MediaCache) DefaultURL() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.defaultURL
+}
+
+func (c *MediaCache) Entry(name string) (MediaCacheEntry, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, exists := c.entries[name]
+	return entry, exists
+}
+
+func (c *MediaCache) Entries() []MediaCacheEntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entries := make([]MediaCacheEntry, 0, len(c.entries))
+	for _, entry := range c.entries {
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b MediaCacheEntry) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return entries
+}
+
+// ResolveURL returns the full URL the client should fetch for a media name, using the
+// message URL, then the URL it was loaded from, then the default URL
+func (c *MediaCache) ResolveURL(name string, messageURL string) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	base := messageURL
+	if base == "" {
+		base = c.entries[name].URL
+	}
+
+	if base == "" {
+		base = c.defaultURL
+	}
+
+	if base == "" {
+		return name
+	}
+
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+
+	return base + name
+}
@@ -0,0 +1,59 @@
+package gmcp
+
+import (
+	"testing"
+
+	"github.com/moodclient/telnet"
+)
+
+func TestMediaCache(t *testing.T) {
+	cache := NewMediaCache()
+
+	if cache.HandleEvent(wireMessage[ClientMediaPlayMessage](t, `{"name": "rain.wav"}`).(telnet.TelOptEvent)) {
+		t.Error("Client.Media.Play should not be handled by the cache")
+	}
+
+	if !cache.HandleEvent(wireMessage[ClientMediaDefaultMessage](t, `{"url": "https://example.com/sounds"}`).(telnet.TelOptEvent)) {
+		t.Fatal("Client.Media.Default was not handled")
+	}
+	if cache.DefaultURL() != "https://example.com/sounds" {
+		t.Errorf("unexpected default URL %q", cache.DefaultURL())
+	}
+
+	if cache.HandleEvent(wireMessage[ClientMediaLoadMessage](t, `{"url": "https://example.com/music/"}`).(telnet.TelOptEvent)) {
+		t.Error("Client.Media.Load without a name should be ignored")
+	}
+
+	cache.HandleEvent(wireMessage[ClientMediaLoadMessage](t, `{"name": "thunder.wav"}`).(telnet.TelOptEvent))
+	cache.HandleEvent(wireMessage[ClientMediaLoadMessage](t, `{"name": "battle.mp3", "url": "https://example.com/music/"}`).(telnet.TelOptEvent))
+
+	entries := cache.Entries()
+	if len(entries) != 2 || entries[0].Name != "battle.mp3" || entries[1].Name != "thunder.wav" {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	thunder, exists := cache.Entry("thunder.wav")
+	if !exists || thunder.URL != "https://example.com/sounds" || thunder.LoadedAt.IsZero() {
+		t.Errorf("thunder.wav should be loaded from the default URL: %+v", thunder)
+	}
+
+	tests := []struct {
+		name       string
+		messageURL string
+		expected   string
+	}{
+		{name: "battle.mp3", messageURL: "https://cdn.example.com", expected: "https://cdn.example.com/battle.mp3"},
+		{name: "battle.mp3", expected: "https://example.com/music/battle.mp3"},
+		{name: "rain.wav", expected: "https://example.com/sounds/rain.wav"},
+	}
+
+	for _, test := range tests {
+		if resolved := cache.ResolveURL(test.name, test.messageURL); resolved != test.expected {
+			t.Errorf("ResolveURL(%q, %q) = %q, expected %q", test.name, test.messageURL, resolved, test.expected)
+		}
+	}
+
+	if resolved := NewMediaCache().ResolveURL("rain.wav", ""); resolved != "rain.wav" {
+		t.Errorf("a cache without URLs should resolve to the bare name, got %q", resolved)
+	}
+}
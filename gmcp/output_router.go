@@ -0,0 +1,104 @@
+package gmcp
+
+import (
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+const MainWindow = "main"
+
+type RoutedText struct {
+	Window string
+	Text   string
+}
+
+// OutputRouter tracks the window that Redirect.Window has asked the client to send text to.
+// Attach routes a terminal's printer output automatically. Without it, printer output should
+// be passed through RouteText, and Prompt should be called whenever a prompt is printed,
+// which sends output back to the main window.
+type OutputRouter struct {
+	lock   sync.Mutex
+	window string
+}
+
+func NewOutputRouter() *OutputRouter {
+	return &OutputRouter{
+		window: MainWindow,
+	}
+}
+
+// Attach routes all of terminal's printer output from now on. Redirect.Window events switch
+// the current window as they arrive, every piece of output is passed to output along with
+// the window it belongs in, and a prompt (IAC GA or IAC EOR) returns output to the main
+// window once it has been passed on.
+func (r *OutputRouter) Attach(terminal *telnet.Terminal, output func(window string, data telnet.TerminalData)) {
+	terminal.RegisterTelOptEventHook(func(_ *telnet.Terminal, event telnet.TelOptEvent) {
+		r.HandleEvent(event)
+	})
+
+	terminal.RegisterPrinterOutputHook(func(_ *telnet.Terminal, data telnet.TerminalData) {
+		window := r.Window()
+		output(window, data)
+
+		if _, isPrompt := data.(telnet.PromptData); isPrompt {
+			r.Reset()
+		}
+	})
+}
+
+// HandleEvent switches the current window when a Redirect.Window message event arrives
+// and returns true if the event was a Redirect.Window message
+func (r *OutputRouter) HandleEvent(event telnet.TelOptEvent) bool {
+	msg, isRedirect := event.(RedirectWindowMessage)
+	if !isRedirect {
+		return false
+	}
+
+	window := msg.Value
+	if window == "" {
+		window = MainWindow
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.window = window
+	return true
+}
+
+func (r *OutputRouter) Window() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.window
+}
+
+// RouteText tags printer text with the window it should be displayed in
+func (r *OutputRouter) RouteText(text string) RoutedText {
+	return RoutedText{
+		Window: r.Window(),
+		Text:   text,
+	}
+}
+
+// Prompt tags a prompt with the current window and then returns output to the main window
+func (r *OutputRouter) Prompt(text string) RoutedText {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	routed := RoutedText{
+		Window: r.window,
+		Text:   text,
+	}
+	r.window = MainWindow
+
+	return routed
+}
+
+func (r *OutputRouter) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.window = MainWindow
+}
@@ -0,0 +1,74 @@
+package gmcp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moodclient/telnet"
+)
+
+func TestOutputRouterAttach(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageRedirect()},
+		[]Package{NewPackageCore(), NewPackageRedirect()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Redirect") }) {
+		t.Fatal("server never saw Redirect support")
+	}
+
+	var lock sync.Mutex
+	windows := make(map[string]string)
+	var prompts []string
+
+	router := NewOutputRouter()
+	router.Attach(pair.Client.Terminal, func(window string, data telnet.TerminalData) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		switch typed := data.(type) {
+		case telnet.TextData:
+			windows[window] += string(typed)
+		case telnet.PromptData:
+			prompts = append(prompts, window)
+		}
+	})
+
+	pair.Server.SendText("before\r\n")
+
+	redirect := RedirectWindowMessage{}
+	redirect.Value = "map"
+	err := server.SendMessage(redirect)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair.Server.SendText("a map line\r\n")
+	pair.Server.Terminal.Keyboard().SendPromptHint()
+	pair.Server.SendText("after\r\n")
+
+	if !pair.Client.WaitForText("after") {
+		t.Fatal("client never received the text after the prompt")
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if !strings.Contains(windows[MainWindow], "before") || !strings.Contains(windows[MainWindow], "after") {
+		t.Errorf("main window got %q", windows[MainWindow])
+	}
+
+	if !strings.Contains(windows["map"], "a map line") || strings.Contains(windows["map"], "after") {
+		t.Errorf("map window got %q", windows["map"])
+	}
+
+	if !slices.Equal(prompts, []string{"map"}) {
+		t.Errorf("expected the prompt to be routed to the map window, got %v", prompts)
+	}
+
+	if router.Window() != MainWindow {
+		t.Errorf("the prompt didn't return output to the main window: %s", router.Window())
+	}
+}
@@ -0,0 +1,36 @@
+package gmcp
+
+// NewIREPackages returns the IRE-style package set: the capitalized Char, Room and Comm
+// packages along with the IRE extensions
+func NewIREPackages() []Package {
+	return []Package{
+		NewPackageCore(),
+		NewPackageChar(),
+		NewPackageCharLogin(),
+		NewPackageCharAfflictions(),
+		NewPackageCharDefences(),
+		NewPackageCharItems(),
+		NewPackageCharSkills(),
+		NewPackageCommChannel(),
+		NewPackageRoom(),
+		NewPackageIRERift(),
+		NewPackageIREComposer(),
+		NewPackageIRETarget(),
+		NewPackageIRETasks(),
+		NewPackageIRETime(),
+		NewPackageIREDisplay(),
+		NewPackageIREMisc(),
+	}
+}
+
+// NewAardwolfPackages returns Aardwolf's lowercase package set. Message lookups prefer exact
+// matches, so this set can be registered alongside NewIREPackages.
+func NewAardwolfPackages() []Package {
+	return []Package{
+		NewPackageCore(),
+		NewPackageAardwolfChar(),
+		NewPackageAardwolfComm(),
+		NewPackageAardwolfRoom(),
+		NewPackageAardwolfGroup(),
+	}
+}
@@ -0,0 +1,352 @@
+package gmcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+var allPackages = []struct {
+	name       string
+	newPackage func() Package
+}{
+	{"Core", NewPackageCore},
+	{"Char", NewPackageChar},
+	{"CharAfflictions", NewPackageCharAfflictions},
+	{"CharDefences", NewPackageCharDefences},
+	{"CharItems", NewPackageCharItems},
+	{"CharLogin", NewPackageCharLogin},
+	{"CharSkills", NewPackageCharSkills},
+	{"Client", NewPackageClient},
+	{"ClientMedia", NewPackageClientMedia},
+	{"CommChannel", NewPackageCommChannel},
+	{"ExternalDiscord", NewPackageExternalDiscord},
+	{"Redirect", NewPackageRedirect},
+	{"Room", NewPackageRoom},
+	{"IRERift", NewPackageIRERift},
+	{"IREComposer", NewPackageIREComposer},
+	{"IRETarget", NewPackageIRETarget},
+	{"IRETasks", NewPackageIRETasks},
+	{"IRETime", NewPackageIRETime},
+	{"IREDisplay", NewPackageIREDisplay},
+	{"IREMisc", NewPackageIREMisc},
+	{"AardwolfChar", NewPackageAardwolfChar},
+	{"AardwolfComm", NewPackageAardwolfComm},
+	{"AardwolfRoom", NewPackageAardwolfRoom},
+	{"AardwolfGroup", NewPackageAardwolfGroup},
+}
+
+const (
+	fromClient = telnet.SideClient
+	fromServer = telnet.SideServer
+)
+
+// wireFixture is a message body written out by hand in the form the IRE, Mudlet and Aardwolf
+// GMCP documentation gives for it
+type wireFixture struct {
+	sender telnet.TerminalSide
+	id     string
+	body   string
+	check  func(t *testing.T, msg Message)
+}
+
+var wireFixtures = []wireFixture{
+	// Core
+	{fromClient, "Core.Hello", `{"client": "Mudlet", "version": "4.17.2"}`, func(t *testing.T, msg Message) {
+		hello := msg.(CoreHelloMessage)
+		if hello.Client != "Mudlet" || hello.Version != "4.17.2" {
+			t.Errorf("unexpected hello: %+v", hello)
+		}
+	}},
+	{fromClient, "Core.Supports.Set", `["Char 1", "Char.Skills 1", "Char.Items 1", "Room 1", "IRE.Rift 1", "IRE.Composer 1"]`, func(t *testing.T, msg Message) {
+		if supports := msg.(CoreSupportsSetMessage).Value; len(supports) != 6 || supports[1] != "Char.Skills 1" {
+			t.Errorf("unexpected supports: %v", supports)
+		}
+	}},
+	{fromClient, "Core.Supports.Add", `["IRE.Target 1"]`, nil},
+	{fromClient, "Core.Supports.Remove", `["IRE.Composer"]`, nil},
+	{fromClient, "Core.KeepAlive", ``, nil},
+	{fromClient, "Core.Ping", `120`, func(t *testing.T, msg Message) {
+		if ping := msg.(CorePingClientMessage).Value; ping != 120 {
+			t.Errorf("expected an average ping of 120, got %d", ping)
+		}
+	}},
+	{fromServer, "Core.Ping", ``, nil},
+	{fromServer, "Core.Goodbye", `"Goodbye, adventurer."`, nil},
+
+	// Char
+	{fromClient, "Char.Login", `{"name": "Bob", "password": "hunter2"}`, nil},
+	{fromServer, "Char.Name", `{"name": "Bob", "fullname": "Bob the Brave"}`, func(t *testing.T, msg Message) {
+		if name := msg.(CharNameMessage); name.Name != "Bob" || name.FullName != "Bob the Brave" {
+			t.Errorf("unexpected name: %+v", name)
+		}
+	}},
+	{fromServer, "Char.Vitals", `{"hp": "4500", "maxhp": "4800", "mp": "4000", "maxmp": "4000", "ep": "15000", "maxep": "15000", "wp": "12000", "maxwp": "12000", "nl": "10", "string": "H:4500/4800 M:4000/4000 E:15000/15000 W:12000/12000 NL:10/100 "}`, func(t *testing.T, msg Message) {
+		vitals := msg.(CharVitalsMessage)
+		if hp, _ := vitals.Int("hp"); hp != 4500 {
+			t.Errorf("expected 4500 hp, got %d", hp)
+		}
+		if maxhp, _ := vitals.Int("maxhp"); maxhp != 4800 {
+			t.Errorf("expected 4800 maxhp, got %d", maxhp)
+		}
+	}},
+	{fromServer, "Char.StatusVars", `{"name": "Name", "fullname": "Full name", "level": "Level", "race": "Race", "class": "Class", "city": "City", "gold": "Gold"}`, nil},
+	{fromServer, "Char.Status", `{"name": "Bob", "fullname": "Bob the Brave", "level": "58 (32%)", "race": "Human", "class": "Sentinel", "city": "Ashtan (1)", "gold": "69", "target": "None", "gender": "male"}`, func(t *testing.T, msg Message) {
+		status := msg.(CharStatusMessage)
+		if city, _ := status.MapMessage.String("city"); city != "Ashtan (1)" {
+			t.Errorf("unexpected city %q", city)
+		}
+	}},
+
+	// Char.Afflictions
+	{fromServer, "Char.Afflictions.List", `[{"name": "weariness", "cure": "eat kelp", "desc": "Reduces the damage you deal"}]`, nil},
+	{fromServer, "Char.Afflictions.Add", `{"name": "clumsiness", "cure": "eat kelp", "desc": "You may miss attacks"}`, func(t *testing.T, msg Message) {
+		if name := msg.(CharAfflictionsAddMessage).Name; name != "clumsiness" {
+			t.Errorf("unexpected affliction %q", name)
+		}
+	}},
+	{fromServer, "Char.Afflictions.Remove", `["weariness"]`, nil},
+
+	// Char.Defences
+	{fromServer, "Char.Defences.List", `[{"name": "deafness", "desc": "deaf"}, {"name": "blindness", "desc": "blind"}]`, nil},
+	{fromServer, "Char.Defences.Add", `{"name": "insomnia", "desc": "insomnia"}`, func(t *testing.T, msg Message) {
+		if name := msg.(CharDefencesAddMessage).Name; name != "insomnia" {
+			t.Errorf("unexpected defence %q", name)
+		}
+	}},
+	{fromServer, "Char.Defences.Remove", `["insomnia"]`, nil},
+
+	// Char.Items
+	{fromClient, "Char.Items.Inv", ``, nil},
+	{fromClient, "Char.Items.Contents", `60573`, nil},
+	{fromClient, "Char.Items.Room", ``, nil},
+	{fromServer, "Char.Items.List", `{"location": "inv", "items": [{"id": "60573", "name": "a rawhide backpack", "icon": "pack", "attrib": "Wc"}, {"id": "1093", "name": "a steel longsword", "icon": "weapon", "attrib": "l"}]}`, func(t *testing.T, msg Message) {
+		items := msg.(CharItemsListMessage).Items
+		if len(items) != 2 || items[0].ID != 60573 || items[0].Attrib&ItemAttContainer == 0 || items[1].Attrib&ItemAttWieldedLeft == 0 {
+			t.Errorf("unexpected items: %+v", items)
+		}
+	}},
+	{fromServer, "Char.Items.Add", `{"location": "room", "item": {"id": "59128", "name": "a sewer rat", "icon": "animal", "attrib": "m"}}`, nil},
+	{fromServer, "Char.Items.Update", `{"location": "inv", "item": {"id": "60573", "name": "a rawhide backpack", "icon": "pack", "attrib": "wc"}}`, nil},
+	{fromServer, "Char.Items.Remove", `{"location": "room", "item": {"id": "59128", "name": "the corpse of a sewer rat"}}`, nil},
+
+	// Char.Login
+	{fromServer, "Char.Login.Default", `{"type": ["password-credentials"], "location": "https://www.example.com/account"}`, nil},
+	{fromServer, "Char.Login.Result", `{"success": false, "message": "Invalid password."}`, nil},
+	{fromClient, "Char.Login.Credentials", `{"account": "bob", "password": "hunter2"}`, nil},
+
+	// Char.Skills
+	{fromClient, "Char.Skills.Get", `{"group": "survival", "name": "gash"}`, nil},
+	{fromServer, "Char.Skills.Groups", `[{"name": "Perception", "rank": "Transcendent (100%)"}, {"name": "Survival", "rank": "Inept (2%)"}]`, func(t *testing.T, msg Message) {
+		if groups := msg.(CharSkillsGroupsMessage).Value; len(groups) != 2 || groups[1].Rank != "Inept (2%)" {
+			t.Errorf("unexpected groups: %+v", groups)
+		}
+	}},
+	{fromServer, "Char.Skills.List", `{"group": "survival", "list": ["Gash", "Vitality", "Fitness"]}`, nil},
+	{fromServer, "Char.Skills.Info", `{"group": "survival", "skill": "gash", "info": "Syntax: GASH <target>"}`, nil},
+
+	// Client
+	{fromServer, "Client.Map", `{"url": "https://www.example.com/map.xml"}`, nil},
+	{fromServer, "Client.GUI", `{"version": "32", "url": "https://www.example.com/package.mpackage"}`, func(t *testing.T, msg Message) {
+		if version := msg.(ClientGUIMessage).Version; version != 32 {
+			t.Errorf("expected version 32, got %d", version)
+		}
+	}},
+
+	// Client.Media
+	{fromServer, "Client.Media.Default", `{"url": "https://www.example.com/sounds/"}`, nil},
+	{fromServer, "Client.Media.Load", `{"name": "sword1.wav", "url": "https://www.example.com/sounds/"}`, nil},
+	{fromServer, "Client.Media.Play", `{"name": "sword1.wav", "type": "sound", "tag": "combat", "volume": 75, "loops": 2, "priority": 60, "key": "sword"}`, func(t *testing.T, msg Message) {
+		play := msg.(ClientMediaPlayMessage)
+		if play.Volume != 75 || play.Loops != 2 || !play.ContinuePlaying() {
+			t.Errorf("unexpected play: %+v", play)
+		}
+	}},
+	{fromServer, "Client.Media.Stop", `{"tag": "combat", "fadeaway": true, "fadeout": 2000}`, nil},
+
+	// Comm.Channel
+	{fromClient, "Comm.Channel.Players", ``, nil},
+	{fromClient, "Comm.Channel.Enable", `"newbie"`, nil},
+	{fromServer, "Comm.Channel.Players", `[{"name": "Seragorn", "channels": ["Newbie", "Market"]}, {"name": "Bob"}]`, nil},
+	{fromServer, "Comm.Channel.List", `[{"name": "ct", "caption": "Ashtan", "command": "ct"}, {"name": "newbie", "caption": "Newbie", "command": "newbie"}]`, nil},
+	{fromServer, "Comm.Channel.Text", `{"channel": "ct", "talker": "Bob", "text": "\u001b[0;1;36m(Ashtan): Bob says, \"Hello.\"\u001b[0;37m"}`, func(t *testing.T, msg Message) {
+		if text := StripANSI(msg.(CommChannelTextMessage).Text); text != `(Ashtan): Bob says, "Hello."` {
+			t.Errorf("unexpected text %q", text)
+		}
+	}},
+
+	// External.Discord
+	{fromServer, "External.Discord.Info", `{"inviteurl": "https://discord.gg/abcdef", "applicationid": "1234567890"}`, nil},
+	{fromServer, "External.Discord.Status", `{"details": "Fighting a sewer rat", "state": "Level 5", "game": "Example MUD", "smallimage": ["server-icon"], "smallimagetext": "Example MUD", "partysize": 2, "partymax": 6, "starttime": 1700000000}`, func(t *testing.T, msg Message) {
+		status := msg.(ExternalDiscordStatusMessage)
+		if !status.Start().Equal(time.Unix(1700000000, 0)) || !status.End().IsZero() {
+			t.Errorf("unexpected times: %v %v", status.Start(), status.End())
+		}
+	}},
+	{fromClient, "External.Discord.Hello", `{"user": "bob#1234", "private": false}`, nil},
+	{fromClient, "External.Discord.Get", ``, nil},
+
+	// Redirect
+	{fromServer, "Redirect.Window", `"map"`, nil},
+
+	// Room
+	{fromServer, "Room.Info", `{"num": 12345, "name": "On a hillside", "area": "Ashtan", "environment": "Hills", "coords": "45,5,4,0", "map": "www.example.com/map.php?45 5 4", "exits": {"n": 12344, "se": 12336}, "details": ["shop", "bank"]}`, func(t *testing.T, msg Message) {
+		info := msg.(RoomInfoMessage)
+		coords, ok := info.ParsedCoords()
+		if !ok || coords.AreaID != 45 || coords.X != 5 || coords.Y != 4 || coords.Z != 0 || info.Exits["se"] != 12336 {
+			t.Errorf("unexpected room: %+v %+v", info, coords)
+		}
+	}},
+	{fromServer, "Room.WrongDir", `"ne"`, nil},
+	{fromServer, "Room.Players", `[{"name": "Tecton", "fullname": "Tecton the Terraformer"}, {"name": "Bob", "fullname": "Bob the Brave"}]`, nil},
+	{fromServer, "Room.AddPlayer", `{"name": "Tecton", "fullname": "Tecton the Terraformer"}`, nil},
+	{fromServer, "Room.RemovePlayer", `"Tecton"`, nil},
+
+	// IRE.Rift
+	{fromClient, "IRE.Rift.Request", ``, nil},
+	{fromServer, "IRE.Rift.List", `[{"name": "bloodroot", "amount": "23", "desc": "bloodroot leaf"}, {"name": "kelp", "amount": "5", "desc": "piece of kelp"}]`, func(t *testing.T, msg Message) {
+		if items := msg.(IRERiftListMessage).Value; len(items) != 2 || items[0].Amount != 23 {
+			t.Errorf("unexpected rift: %+v", items)
+		}
+	}},
+	{fromServer, "IRE.Rift.Change", `{"name": "bloodroot", "amount": "22", "desc": "bloodroot leaf"}`, nil},
+
+	// IRE.Composer
+	{fromServer, "IRE.Composer.Edit", `{"title": "Journal", "text": "Dear diary,\nToday I slew a rat."}`, nil},
+	{fromClient, "IRE.Composer.SetBuffer", `"Dear diary,\nToday I slew two rats."`, nil},
+
+	// IRE.Target
+	{fromClient, "IRE.Target.Set", `"59128"`, nil},
+	{fromClient, "IRE.Target.Request", ``, nil},
+	{fromServer, "IRE.Target.Set", `"59128"`, nil},
+	{fromServer, "IRE.Target.Info", `{"id": "59128", "short_desc": "a sewer rat", "hpperc": "100%"}`, nil},
+
+	// IRE.Tasks
+	{fromClient, "IRE.Tasks.Request", ``, nil},
+	{fromServer, "IRE.Tasks.List", `[{"id": "1", "name": "Slay a rat", "desc": "Kill a rat in the sewers", "type": "quests", "cmd": "QUEST INFO 1", "status": "0", "group": "Newbie"}]`, nil},
+	{fromServer, "IRE.Tasks.Update", `[{"id": "1", "name": "Slay a rat", "desc": "Kill a rat in the sewers", "type": "quests", "cmd": "QUEST INFO 1", "status": "1", "group": "Newbie"}]`, nil},
+	{fromServer, "IRE.Tasks.Completed", `[{"id": "1", "name": "Slay a rat", "desc": "Kill a rat in the sewers", "type": "quests", "cmd": "QUEST INFO 1", "status": "1", "group": "Newbie"}]`, nil},
+
+	// IRE.Time
+	{fromClient, "IRE.Time.Request", ``, nil},
+	{fromServer, "IRE.Time.List", `{"day": "14", "mon": "3", "month": "Tzarin", "year": "650", "hour": "12", "daynight": "100"}`, func(t *testing.T, msg Message) {
+		list := msg.(IRETimeListMessage)
+		if month, _ := list.MonthName(); month != "Tzarin" {
+			t.Errorf("unexpected month %q", month)
+		}
+	}},
+	{fromServer, "IRE.Time.Update", `{"hour": "13", "daynight": "101"}`, nil},
+
+	// IRE.Display
+	{fromServer, "IRE.Display.FixedFont", `"start"`, nil},
+	{fromServer, "IRE.Display.Ohmap", `"stop"`, nil},
+
+	// IRE.Misc
+	{fromClient, "IRE.Misc.Voted", `"tms"`, nil},
+	{fromServer, "IRE.Misc.RemindVote", `"https://www.topmudsites.com/vote-achaea.html"`, nil},
+	{fromServer, "IRE.Misc.Achievement", `[{"name": "Ratslayer", "value": "1"}]`, nil},
+	{fromServer, "IRE.Misc.URL", `[{"url": "https://www.example.com/news", "window": "_blank"}]`, nil},
+	{fromServer, "IRE.Misc.Tip", `"Use HELP to browse the help files."`, nil},
+
+	// Aardwolf
+	{fromServer, "char.base", `{"name": "Lasher", "class": "Warrior", "subclass": "Soldier", "race": "Human", "clan": "", "pretitle": "", "perlevel": 1000, "tier": 0, "remorts": 1, "redos": "0"}`, nil},
+	{fromServer, "char.vitals", `{"hp": 4850, "mana": 3990, "moves": 2300}`, func(t *testing.T, msg Message) {
+		if vitals := msg.(AardwolfCharVitalsMessage); vitals.HP != 4850 || vitals.Moves != 2300 {
+			t.Errorf("unexpected vitals: %+v", vitals)
+		}
+	}},
+	{fromServer, "char.stats", `{"str": 25, "int": 25, "wis": 25, "dex": 25, "con": 25, "luck": 25, "hr": 250, "dr": 300, "saves": 0}`, nil},
+	{fromServer, "char.maxstats", `{"maxhp": 5000, "maxmana": 4000, "maxmoves": 2300, "maxstr": 25, "maxint": 25, "maxwis": 25, "maxdex": 25, "maxcon": 25, "maxluck": 25}`, nil},
+	{fromServer, "char.status", `{"level": 201, "tnl": 0, "hunger": 100, "thirst": 100, "align": 2500, "state": 3, "pos": "Standing", "enemy": "", "enemypct": 0}`, nil},
+	{fromServer, "char.worth", `{"gold": 1000, "bank": 5000, "qp": 100, "tp": 1, "trains": 5, "pracs": 10}`, nil},
+	{fromServer, "comm.channel", `{"chan": "gossip", "msg": "Bob gossips 'hello'", "player": "Bob"}`, nil},
+	{fromServer, "comm.tick", ``, nil},
+	{fromServer, "comm.quest", `{"action": "start", "targ": "a sewer rat", "room": "The sewers", "area": "Aylor", "timer": 30}`, nil},
+	{fromServer, "room.info", `{"num": 32418, "name": "The Grand City of Aylor", "zone": "aylor", "terrain": "city", "details": "", "exits": {"n": 32419, "s": 32417}, "coord": {"id": 0, "x": 30, "y": 19, "cont": 0}}`, func(t *testing.T, msg Message) {
+		if info := msg.(AardwolfRoomInfoMessage); info.Number != 32418 || info.Coord.X != 30 || info.Exits["n"] != 32419 {
+			t.Errorf("unexpected room: %+v", info)
+		}
+	}},
+	{fromServer, "group", `{"groupname": "Bob's group", "leader": "Bob", "created": "19 Oct 12:00", "status": "Private", "count": 2, "kills": 10, "exp": 1000, "members": [{"name": "Bob", "info": {"hp": 100, "mhp": 100, "mn": 50, "mmn": 50, "mv": 100, "mmv": 100, "align": 0, "tnl": 500, "qt": 0, "qs": 0, "lvl": 10, "here": 1}}]}`, nil},
+}
+
+func TestPackageMessagesRoundTrip(t *testing.T) {
+	type registered struct {
+		data   MessageData
+		schema *JSONSchema
+	}
+
+	messages := make(map[string]registered)
+	for _, test := range allPackages {
+		pkg := test.newPackage()
+
+		schema, err := pkg.Schema()
+		if err != nil {
+			t.Fatalf("%s: schema: %v", test.name, err)
+		}
+
+		for index, message := range pkg.Messages {
+			key := senderName(message.Sender) + " " + schema.Messages[index].ID
+			messages[key] = registered{data: message, schema: schema.Messages[index].Schema}
+		}
+	}
+
+	covered := make(map[string]bool)
+	for _, fixture := range wireFixtures {
+		key := senderName(fixture.sender) + " " + fixture.id
+		covered[key] = true
+
+		t.Run(key, func(t *testing.T) {
+			message, exists := messages[key]
+			if !exists {
+				t.Fatal("no package registers this message")
+			}
+
+			first, err := message.data.Create(nil, json.RawMessage(fixture.body))
+			if err != nil {
+				t.Fatalf("unmarshal %s: %v", fixture.body, err)
+			}
+
+			if first.ID() != fixture.id {
+				t.Errorf("expected ID %q, got %q", fixture.id, first.ID())
+			}
+
+			if fixture.check != nil {
+				fixture.check(t, first)
+			}
+
+			firstJson, err := json.Marshal(first)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := message.schema.Validate(firstJson); err != nil {
+				t.Errorf("marshalled message does not match schema: %v", err)
+			}
+
+			second, err := message.data.Create(nil, firstJson)
+			if err != nil {
+				t.Fatalf("unmarshal %s: %v", firstJson, err)
+			}
+
+			secondJson, err := json.Marshal(second)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(firstJson, secondJson) {
+				t.Errorf("marshalling is not symmetric:\n first: %s\nsecond: %s", firstJson, secondJson)
+			}
+		})
+	}
+
+	// Every registered message must round-trip, so none may go without a fixture
+	for key := range messages {
+		if !covered[key] {
+			t.Errorf("%s has no wire fixture", key)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gmcp
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/moodclient/telnet/telopts"
+)
+
+var ErrUnknownMessage = errors.New("unknown message")
+
+type ParsePolicy int
+
+const (
+	// ParsePolicyLenient raises unregistered messages as UnknownMessage and returns
+	// JSON errors to the terminal
+	ParsePolicyLenient ParsePolicy = iota
+	// ParsePolicyStrict returns an error to the terminal for unregistered messages
+	// as well as JSON errors
+	ParsePolicyStrict
+	// ParsePolicyQuarantine raises a MalformedMessage event for unregistered messages
+	// and JSON errors instead of returning an error to the terminal
+	ParsePolicyQuarantine
+)
+
+func (p ParsePolicy) String() string {
+	switch p {
+	case ParsePolicyLenient:
+		return "Lenient"
+	case ParsePolicyStrict:
+		return "Strict"
+	case ParsePolicyQuarantine:
+		return "Quarantine"
+	default:
+		return "Unknown"
+	}
+}
+
+type MalformedMessage struct {
+	telopts.BaseTelOptEvent
+
+	MessageID string
+	Raw       json.RawMessage
+	Err       error
+}
+
+func (m MalformedMessage) String() string {
+	var sb strings.Builder
+	sb.WriteString("Malformed GMCP Message: ")
+	sb.WriteString(m.MessageID)
+	sb.WriteByte(' ')
+	sb.WriteByte('-')
+	sb.WriteByte(' ')
+	sb.WriteString(string(m.Raw))
+	sb.WriteString(" (")
+	sb.WriteString(m.Err.Error())
+	sb.WriteByte(')')
+
+	return sb.String()
+}
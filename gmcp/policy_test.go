@@ -0,0 +1,96 @@
+package gmcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestParsePolicy(t *testing.T) {
+	pair, _, server := startGMCPPair(t, []Package{NewPackageCore()}, []Package{NewPackageCore()})
+
+	if server.ParsePolicy() != ParsePolicyLenient {
+		t.Errorf("expected the default policy to be Lenient, got %s", server.ParsePolicy())
+	}
+
+	unknown := []byte(`Made.Up {"value": 1}`)
+	badJson := []byte(`Core.Hello {"client": `)
+
+	countEvents := func() (unknownCount int, malformedCount int) {
+		for _, event := range pair.Server.Events() {
+			switch event.(type) {
+			case UnknownMessage:
+				unknownCount++
+			case MalformedMessage:
+				malformedCount++
+			}
+		}
+
+		return unknownCount, malformedCount
+	}
+
+	// Lenient keeps the baseline behaviour: unknown messages are raised as UnknownMessage
+	// and only bad JSON is an error
+	err := server.Subnegotiate(unknown)
+	if err != nil {
+		t.Fatalf("lenient: unknown message returned %v", err)
+	}
+
+	msg, ok := telnettest.WaitForEventType[UnknownMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("lenient: unknown message was not raised")
+	}
+
+	if msg.ID() != "Made.Up" {
+		t.Errorf("lenient: unexpected ID %q", msg.ID())
+	}
+
+	if value, _ := msg.Int("value"); value != 1 {
+		t.Errorf("lenient: unexpected value %d", value)
+	}
+
+	if err := server.Subnegotiate(badJson); err == nil {
+		t.Error("lenient: bad JSON was accepted")
+	}
+
+	// Strict rejects both, without raising anything
+	server.SetParsePolicy(ParsePolicyStrict)
+	pair.Server.ClearEvents()
+
+	err = server.Subnegotiate(unknown)
+	if !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("strict: expected ErrUnknownMessage, got %v", err)
+	}
+
+	if err := server.Subnegotiate(badJson); err == nil {
+		t.Error("strict: bad JSON was accepted")
+	}
+
+	// Quarantine raises both as MalformedMessage instead of returning an error
+	server.SetParsePolicy(ParsePolicyQuarantine)
+
+	for _, subnegotiation := range [][]byte{unknown, badJson} {
+		if err := server.Subnegotiate(subnegotiation); err != nil {
+			t.Errorf("quarantine: %s returned %v", subnegotiation, err)
+		}
+	}
+
+	if !pair.Server.Wait(func() bool {
+		_, malformedCount := countEvents()
+		return malformedCount == 2
+	}) {
+		t.Fatal("quarantine: malformed messages were not raised")
+	}
+
+	if unknownCount, _ := countEvents(); unknownCount != 0 {
+		t.Errorf("unknown messages were raised under strict or quarantine: %d", unknownCount)
+	}
+
+	malformed, _ := telnettest.WaitForEventType(pair.Server, func(event MalformedMessage) bool {
+		return event.MessageID == "Made.Up"
+	})
+	if !errors.Is(malformed.Err, ErrUnknownMessage) {
+		t.Errorf("quarantine: expected ErrUnknownMessage, got %v", malformed.Err)
+	}
+}
@@ -0,0 +1,268 @@
+package gmcp
+
+import (
+	"strings"
+	"time"
+)
+
+type RateLimit struct {
+	// PerSecond is the average number of messages allowed per second. Zero or less disables
+	// the limit.
+	PerSecond float64
+	// Burst is the number of messages that can be sent back to back before the limit
+	// applies. Defaults to 1.
+	Burst int
+	// Coalesce keeps the latest message over the limit and sends it when the limit allows,
+	// instead of dropping it. Any older pending message with the same ID is discarded, which
+	// loses the contents of partial updates such as Char.Status deltas unless the sender
+	// resends them. DeltaSender does: it only treats values as received once they are
+	// written, so its next message includes anything that was coalesced away.
+	Coalesce bool
+}
+
+type SendStats struct {
+	Sent      uint64
+	Dropped   uint64
+	Coalesced uint64
+}
+
+func (s SendStats) add(other SendStats) SendStats {
+	return SendStats{
+		Sent:      s.Sent + other.Sent,
+		Dropped:   s.Dropped + other.Dropped,
+		Coalesced: s.Coalesced + other.Coalesced,
+	}
+}
+
+// SendResult reports what happened to a message passed to TrySendMessage
+type SendResult int
+
+const (
+	// SendResultSent means the message was written to the terminal
+	SendResultSent SendResult = iota
+	// SendResultSkipped means GMCP is not active or the client doesn't support the message's
+	// package, so the message was not sent
+	SendResultSkipped
+	// SendResultDropped means the message was over its rate limit and was discarded
+	SendResultDropped
+	// SendResultCoalesced means the message was over its rate limit and is waiting to be sent.
+	// It will be discarded if a newer message with the same ID arrives first.
+	SendResultCoalesced
+)
+
+func (r SendResult) String() string {
+	switch r {
+	case SendResultSent:
+		return "Sent"
+	case SendResultSkipped:
+		return "Skipped"
+	case SendResultDropped:
+		return "Dropped"
+	case SendResultCoalesced:
+		return "Coalesced"
+	default:
+		return "Unknown"
+	}
+}
+
+type sendBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+
+	hasPending bool
+	pendingID  string
+	pending    []byte
+	onSent     func()
+	timer      *time.Timer
+}
+
+func (b *sendBucket) refill(now time.Time) {
+	burst := float64(max(b.limit.Burst, 1))
+
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*b.limit.PerSecond)
+	}
+
+	b.last = now
+}
+
+func (b *sendBucket) wait() time.Duration {
+	return time.Duration((1 - b.tokens) / b.limit.PerSecond * float64(time.Second))
+}
+
+// SetRateLimit limits how often messages with the given ID are sent. It applies to
+// SendMessage, SendRaw and messages the option sends itself. Removing a limit with a
+// PerSecond of zero or less sends any message that was waiting on it immediately.
+func (g *GMCP) SetRateLimit(messageID string, limit RateLimit) {
+	onSent := g.setRateLimit(messageID, limit)
+
+	// The callback may send more messages, so it runs without parseLock
+	if onSent != nil {
+		onSent()
+	}
+}
+
+// setRateLimit applies a rate limit and returns the callback of a waiting message it sent
+func (g *GMCP) setRateLimit(messageID string, limit RateLimit) func() {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	key := strings.ToUpper(messageID)
+
+	bucket, exists := g.sendBuckets[key]
+	if limit.PerSecond <= 0 {
+		if exists {
+			if bucket.timer != nil {
+				bucket.timer.Stop()
+			}
+			delete(g.sendBuckets, key)
+
+			// Nothing limits the message that was waiting anymore, so send it now
+			if bucket.hasPending {
+				return g.writePending(key, bucket)
+			}
+		}
+		return nil
+	}
+
+	if !exists {
+		bucket = &sendBucket{}
+		g.sendBuckets[key] = bucket
+	}
+
+	bucket.limit = limit
+	return nil
+}
+
+func (g *GMCP) SendStats(messageID string) SendStats {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	return g.sendStats[strings.ToUpper(messageID)]
+}
+
+func (g *GMCP) TotalSendStats() SendStats {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	var total SendStats
+	for _, stats := range g.sendStats {
+		total = total.add(stats)
+	}
+
+	return total
+}
+
+// writeLimited writes a message if its rate limit allows, otherwise drops or coalesces it.
+// onSent is kept with a coalesced message and called when it is written. Must be called with
+// parseLock held.
+func (g *GMCP) writeLimited(id string, rawJson []byte, onSent func()) (SendResult, error) {
+	key := strings.ToUpper(id)
+	stats := g.sendStats[key]
+	defer func() {
+		g.sendStats[key] = stats
+	}()
+
+	bucket, limited := g.sendBuckets[key]
+	if !limited {
+		stats.Sent++
+		return SendResultSent, g.writeMessage(id, rawJson)
+	}
+
+	bucket.refill(time.Now())
+
+	// A newer message can't overtake one that is already waiting
+	if bucket.hasPending {
+		stats.Coalesced++
+		bucket.pendingID = id
+		bucket.pending = rawJson
+		bucket.onSent = onSent
+		return SendResultCoalesced, nil
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		stats.Sent++
+		return SendResultSent, g.writeMessage(id, rawJson)
+	}
+
+	if !bucket.limit.Coalesce {
+		stats.Dropped++
+		return SendResultDropped, nil
+	}
+
+	bucket.hasPending = true
+	bucket.pendingID = id
+	bucket.pending = rawJson
+	bucket.onSent = onSent
+	bucket.timer = time.AfterFunc(bucket.wait(), func() {
+		g.flushPending(key)
+	})
+
+	return SendResultCoalesced, nil
+}
+
+func (g *GMCP) flushPending(key string) {
+	onSent := g.writeDuePending(key)
+
+	// The callback may send more messages, so it runs without parseLock
+	if onSent != nil {
+		onSent()
+	}
+}
+
+// writeDuePending sends a bucket's waiting message if its rate limit allows, otherwise waits
+// again. It returns the callback of the message if it was written.
+func (g *GMCP) writeDuePending(key string) func() {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	bucket, limited := g.sendBuckets[key]
+	if !limited || !bucket.hasPending {
+		return nil
+	}
+
+	bucket.refill(time.Now())
+	if bucket.tokens < 1 {
+		bucket.timer = time.AfterFunc(bucket.wait(), func() {
+			g.flushPending(key)
+		})
+		return nil
+	}
+
+	bucket.tokens--
+	return g.writePending(key, bucket)
+}
+
+// writePending sends a bucket's waiting message, or counts it as dropped if it can no longer
+// be sent. It returns the message's callback if the message was written, for the caller to
+// run once parseLock is released. Must be called with parseLock held.
+func (g *GMCP) writePending(key string, bucket *sendBucket) func() {
+	id, rawJson, onSent := bucket.pendingID, bucket.pending, bucket.onSent
+	bucket.hasPending = false
+	bucket.pendingID = ""
+	bucket.pending = nil
+	bucket.onSent = nil
+	bucket.timer = nil
+
+	stats := g.sendStats[key]
+	defer func() {
+		g.sendStats[key] = stats
+	}()
+
+	// The connection may have gone away while the message was waiting
+	if !g.canSend(id) {
+		stats.Dropped++
+		return nil
+	}
+
+	stats.Sent++
+	if g.writeMessage(id, rawJson) != nil {
+		return nil
+	}
+
+	return onSent
+}
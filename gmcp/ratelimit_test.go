@@ -0,0 +1,94 @@
+package gmcp
+
+import (
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func vitalsMessage(hp int) CharVitalsMessage {
+	msg := CharVitalsMessage{MapMessage: NewMapMessage("string")}
+	msg.SetValue("hp", hp)
+	return msg
+}
+
+func vitalsHP(event CharVitalsMessage) int {
+	hp, _ := event.Int("hp")
+	return hp
+}
+
+func TestRateLimit(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	// The bucket effectively never refills, so only the burst gets through
+	server.SetRateLimit("Char.Vitals", RateLimit{PerSecond: 0.001})
+
+	sends := []struct {
+		msg      Message
+		expected SendResult
+	}{
+		{vitalsMessage(1), SendResultSent},
+		{vitalsMessage(2), SendResultDropped},
+		// The client doesn't support Room
+		{RoomInfoMessage{Number: 1}, SendResultSkipped},
+	}
+
+	for _, send := range sends {
+		result, err := server.TrySendMessage(send.msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != send.expected {
+			t.Errorf("%s: expected %s, got %s", send.msg.ID(), send.expected, result)
+		}
+	}
+
+	// Message IDs are matched regardless of case
+	server.SetRateLimit("char.vitals", RateLimit{PerSecond: 0.001, Coalesce: true})
+
+	for _, hp := range []int{3, 4} {
+		result, err := server.TrySendMessage(vitalsMessage(hp))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != SendResultCoalesced {
+			t.Errorf("hp %d: expected Coalesced, got %s", hp, result)
+		}
+	}
+
+	// Removing the limit sends the latest waiting message straight away
+	server.SetRateLimit("Char.Vitals", RateLimit{})
+
+	if _, ok := telnettest.WaitForEventType(pair.Client, func(event CharVitalsMessage) bool {
+		return vitalsHP(event) == 4
+	}); !ok {
+		t.Fatal("pending vitals were never sent")
+	}
+
+	for _, event := range pair.Client.Events() {
+		if vitals, isVitals := event.(CharVitalsMessage); isVitals && vitalsHP(vitals) != 1 && vitalsHP(vitals) != 4 {
+			t.Errorf("client received vitals that should not have been sent: %v", vitals)
+		}
+	}
+
+	// Each message is counted once: hp 3 was replaced by hp 4 while waiting, and hp 4 was sent
+	expected := SendStats{Sent: 2, Dropped: 1, Coalesced: 1}
+	if stats := server.SendStats("Char.Vitals"); stats != expected {
+		t.Errorf("expected stats %+v, got %+v", expected, stats)
+	}
+
+	// With no limit, everything is sent
+	result, err := server.TrySendMessage(vitalsMessage(5))
+	if err != nil || result != SendResultSent {
+		t.Errorf("expected Sent after removing the limit, got %s %v", result, err)
+	}
+}
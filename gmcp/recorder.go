@@ -0,0 +1,268 @@
+package gmcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+type TrafficDirection string
+
+const (
+	TrafficIncoming TrafficDirection = "in"
+	TrafficOutgoing TrafficDirection = "out"
+)
+
+type TrafficRecord struct {
+	Time      time.Time        `json:"time"`
+	Direction TrafficDirection `json:"direction"`
+	ID        string           `json:"id"`
+	// Data is the raw JSON as it was sent or received, with any login password redacted.
+	// It is stored as a string because malformed messages are recorded too.
+	Data string `json:"data,omitempty"`
+}
+
+// Subnegotiation rebuilds the GMCP subnegotiation the record was captured from
+func (r TrafficRecord) Subnegotiation() []byte {
+	if r.Data == "" {
+		return []byte(r.ID)
+	}
+
+	return []byte(r.ID + " " + r.Data)
+}
+
+type registeredTrafficHook struct {
+	id   int
+	hook TrafficHook
+}
+
+// TrafficHook is called for every GMCP message sent or received. Outgoing messages are
+// observed while the GMCP lock is held, so hooks must not call back into the GMCP.
+type TrafficHook func(record TrafficRecord)
+
+// AddTrafficHook registers hook and returns an ID that can be passed to RemoveTrafficHook
+func (g *GMCP) AddTrafficHook(hook TrafficHook) int {
+	g.trafficLock.Lock()
+	defer g.trafficLock.Unlock()
+
+	g.nextTrafficHookID++
+	g.trafficHooks = append(g.trafficHooks, registeredTrafficHook{
+		id:   g.nextTrafficHookID,
+		hook: hook,
+	})
+
+	return g.nextTrafficHookID
+}
+
+// RemoveTrafficHook unregisters the hook that AddTrafficHook returned id for. Removing a hook
+// that is not registered does nothing.
+func (g *GMCP) RemoveTrafficHook(id int) {
+	g.trafficLock.Lock()
+	defer g.trafficLock.Unlock()
+
+	g.trafficHooks = slices.DeleteFunc(g.trafficHooks, func(registered registeredTrafficHook) bool {
+		return registered.id == id
+	})
+}
+
+func (g *GMCP) observeTraffic(direction TrafficDirection, id string, rawJson []byte) {
+	g.trafficLock.Lock()
+	hooks := slices.Clone(g.trafficHooks)
+	g.trafficLock.Unlock()
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	record := TrafficRecord{
+		Time:      time.Now(),
+		Direction: direction,
+		ID:        id,
+		Data:      string(rawJson),
+	}
+
+	for _, registered := range hooks {
+		registered.hook(record)
+	}
+}
+
+// Recorder writes GMCP traffic to a stream as JSON lines, one TrafficRecord per line.
+// Login passwords are already redacted by the time traffic reaches a hook, so recordings
+// can be shared.
+type Recorder struct {
+	lock    sync.Mutex
+	writer  *bufio.Writer
+	closer  io.Closer
+	encoder *json.Encoder
+	err     error
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	writer := bufio.NewWriter(w)
+
+	return &Recorder{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+func CreateRecordingFile(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := NewRecorder(file)
+	recorder.closer = file
+
+	return recorder, nil
+}
+
+// Attach records all traffic sent or received by g. The returned ID can be passed to
+// g.RemoveTrafficHook to stop recording.
+func (r *Recorder) Attach(g *GMCP) int {
+	return g.AddTrafficHook(r.Record)
+}
+
+func (r *Recorder) Record(record TrafficRecord) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	r.err = r.encoder.Encode(record)
+}
+
+// Err returns the first error encountered while writing
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.err
+}
+
+func (r *Recorder) Flush() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.writer.Flush()
+}
+
+func (r *Recorder) Close() error {
+	err := r.Flush()
+
+	if r.closer != nil {
+		closeErr := r.closer.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+func ReadRecording(r io.Reader) ([]TrafficRecord, error) {
+	var records []TrafficRecord
+
+	decoder := json.NewDecoder(r)
+	for {
+		var record TrafficRecord
+		err := decoder.Decode(&record)
+		if err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, fmt.Errorf("recording: record %d: %w", len(records)+1, err)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func ReadRecordingFile(path string) ([]TrafficRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadRecording(file)
+}
+
+type ReplayOptions struct {
+	// RespectTiming waits between messages for the time that passed when they were recorded
+	RespectTiming bool
+	// StopOnError stops the replay at the first message that Subnegotiate rejects
+	StopOnError bool
+}
+
+type ReplayResult struct {
+	Delivered int
+	Errors    []error
+	// Outgoing holds the messages g sent in response during the replay
+	Outgoing []TrafficRecord
+}
+
+// Replay feeds the incoming messages from a recording back through g.Subnegotiate, as though
+// they had arrived from the remote side. g must be registered on a terminal, such as one half
+// of an in-memory terminal pair.
+func Replay(g *GMCP, records []TrafficRecord, options ReplayOptions) ReplayResult {
+	var result ReplayResult
+
+	var outgoingLock sync.Mutex
+	var outgoing []TrafficRecord
+	stopped := false
+
+	hookID := g.AddTrafficHook(func(record TrafficRecord) {
+		outgoingLock.Lock()
+		defer outgoingLock.Unlock()
+
+		if !stopped && record.Direction == TrafficOutgoing {
+			outgoing = append(outgoing, record)
+		}
+	})
+
+	var last time.Time
+	for _, record := range records {
+		if record.Direction != TrafficIncoming {
+			continue
+		}
+
+		if options.RespectTiming && !last.IsZero() && record.Time.After(last) {
+			time.Sleep(record.Time.Sub(last))
+		}
+		last = record.Time
+
+		err := g.Subnegotiate(record.Subnegotiation())
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("replay: %s: %w", record.ID, err))
+			if options.StopOnError {
+				break
+			}
+			continue
+		}
+
+		result.Delivered++
+	}
+
+	g.RemoveTrafficHook(hookID)
+
+	// A message observed just before the hook was removed can still call it afterwards, so
+	// stop it from appending to the slice that is returned
+	outgoingLock.Lock()
+	stopped = true
+	result.Outgoing = outgoing
+	outgoingLock.Unlock()
+
+	return result
+}
@@ -0,0 +1,108 @@
+package gmcp
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+
+	recorder, err := CreateRecordingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, client, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar()},
+	)
+	hookID := recorder.Attach(client)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	for _, hp := range []int{100, 90} {
+		err = server.SendMessage(vitalsMessage(hp))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := telnettest.WaitForEventType(pair.Client, func(vitals CharVitalsMessage) bool {
+			return vitalsHP(vitals) == hp
+		}); !ok {
+			t.Fatalf("client never received hp %d", hp)
+		}
+	}
+
+	err = client.SendMessage(CoreKeepAliveMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.RemoveTrafficHook(hookID)
+
+	err = recorder.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ReadRecordingFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var incoming []string
+	var outgoing []string
+	for _, record := range records {
+		if record.Direction == TrafficIncoming {
+			incoming = append(incoming, string(record.Subnegotiation()))
+		} else {
+			outgoing = append(outgoing, record.ID)
+		}
+	}
+
+	if len(incoming) != 2 || incoming[0] != `Char.Vitals {"hp":100}` {
+		t.Errorf("unexpected incoming records %q", incoming)
+	}
+
+	// The handshake may or may not have been recorded, depending on when the recorder attached
+	if len(outgoing) == 0 || outgoing[len(outgoing)-1] != "Core.KeepAlive" {
+		t.Errorf("unexpected outgoing records %q", outgoing)
+	}
+
+	replayPair, replayClient, _ := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar()},
+	)
+
+	result := Replay(replayClient, records, ReplayOptions{StopOnError: true})
+	if result.Delivered != 2 || len(result.Errors) > 0 {
+		t.Fatalf("expected 2 messages delivered, got %d %v", result.Delivered, result.Errors)
+	}
+
+	for _, hp := range []int{100, 90} {
+		if _, ok := telnettest.WaitForEventType(replayPair.Client, func(vitals CharVitalsMessage) bool {
+			return vitalsHP(vitals) == hp
+		}); !ok {
+			t.Errorf("replay never delivered hp %d", hp)
+		}
+	}
+}
+
+func TestReadRecordingError(t *testing.T) {
+	recording := `{"direction": "in", "id": "Core.Ping"}` + "\n" + `{"direction": "in", "id":` + "\n"
+
+	records, err := ReadRecording(strings.NewReader(recording))
+	if err == nil || !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("expected an error for record 2, got %v", err)
+	}
+
+	if len(records) != 1 || records[0].ID != "Core.Ping" {
+		t.Errorf("expected the records before the error, got %+v", records)
+	}
+}
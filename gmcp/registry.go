@@ -0,0 +1,71 @@
+package gmcp
+
+import (
+	"slices"
+	"strings"
+)
+
+type registeredMessage struct {
+	packageID string
+	create    MessageFactory
+}
+
+// messageRegistry looks up message factories by ID. Exact matches take priority, so that
+// packages whose IDs differ only by case (such as IRE's Room.Info and Aardwolf's room.info)
+// can be registered side by side. Lookups that don't match exactly fall back to a
+// case-insensitive match, preferring the most recently registered message.
+type messageRegistry struct {
+	exact  map[string]registeredMessage
+	folded map[string][]string
+}
+
+func newMessageRegistry() messageRegistry {
+	return messageRegistry{
+		exact:  make(map[string]registeredMessage),
+		folded: make(map[string][]string),
+	}
+}
+
+func (r messageRegistry) add(id string, message registeredMessage) {
+	r.exact[id] = message
+
+	foldedID := strings.ToUpper(id)
+	ids := slices.DeleteFunc(r.folded[foldedID], func(existing string) bool {
+		return existing == id
+	})
+	r.folded[foldedID] = append(ids, id)
+}
+
+func (r messageRegistry) remove(id string, packageID string) {
+	existing, exists := r.exact[id]
+	if !exists || existing.packageID != packageID {
+		return
+	}
+
+	delete(r.exact, id)
+
+	foldedID := strings.ToUpper(id)
+	ids := slices.DeleteFunc(r.folded[foldedID], func(existing string) bool {
+		return existing == id
+	})
+
+	if len(ids) == 0 {
+		delete(r.folded, foldedID)
+	} else {
+		r.folded[foldedID] = ids
+	}
+}
+
+func (r messageRegistry) lookup(id string) (registeredMessage, bool) {
+	message, exists := r.exact[id]
+	if exists {
+		return message, true
+	}
+
+	ids := r.folded[strings.ToUpper(id)]
+	if len(ids) == 0 {
+		return registeredMessage{}, false
+	}
+
+	return r.exact[ids[len(ids)-1]], true
+}
@@ -1,6 +1,11 @@
 package gmcp
 
-import "github.com/moodclient/telnet"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moodclient/telnet"
+)
 
 func NewPackageRoom() Package {
 	return Package{
@@ -48,6 +53,144 @@ func (m RoomInfoMessage) ID() string {
 	return "Room.Info"
 }
 
+func (m RoomInfoMessage) ParsedCoords() (RoomCoords, bool) {
+	return ParseRoomCoords(m.Coords)
+}
+
+func (m RoomInfoMessage) ParsedMap() (RoomMap, bool) {
+	return ParseRoomMap(m.Map)
+}
+
+func (m RoomInfoMessage) DetailFlags() RoomDetails {
+	return ParseRoomDetails(m.Details)
+}
+
+type RoomCoords struct {
+	AreaID   int
+	X        int
+	Y        int
+	Z        int
+	Building bool
+}
+
+func (c RoomCoords) String() string {
+	building := "0"
+	if c.Building {
+		building = "1"
+	}
+
+	return strings.Join([]string{
+		strconv.Itoa(c.AreaID),
+		strconv.Itoa(c.X),
+		strconv.Itoa(c.Y),
+		strconv.Itoa(c.Z),
+		building,
+	}, ",")
+}
+
+// ParseRoomCoords parses IRE-style "area,x,y,z,building" coordinates. The building flag
+// is optional.
+func ParseRoomCoords(s string) (RoomCoords, bool) {
+	fields := strings.Split(s, ",")
+	if len(fields) < 4 || len(fields) > 5 {
+		return RoomCoords{}, false
+	}
+
+	var values [5]int
+	for i, field := range fields {
+		value, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return RoomCoords{}, false
+		}
+
+		values[i] = value
+	}
+
+	return RoomCoords{
+		AreaID:   values[0],
+		X:        values[1],
+		Y:        values[2],
+		Z:        values[3],
+		Building: values[4] != 0,
+	}, true
+}
+
+type RoomMap struct {
+	URL string
+	X   int
+	Y   int
+}
+
+// ParseRoomMap parses IRE-style "url x y" map locations
+func ParseRoomMap(s string) (RoomMap, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return RoomMap{}, false
+	}
+
+	x, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return RoomMap{}, false
+	}
+
+	y, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return RoomMap{}, false
+	}
+
+	return RoomMap{
+		URL: fields[0],
+		X:   x,
+		Y:   y,
+	}, true
+}
+
+type RoomDetails int
+
+const (
+	RoomDetailShop RoomDetails = 1 << iota
+	RoomDetailBank
+	RoomDetailPostOffice
+	RoomDetailWilderness
+	RoomDetailSubdivision
+)
+
+var roomDetailNames = []struct {
+	detail RoomDetails
+	name   string
+}{
+	{RoomDetailShop, "shop"},
+	{RoomDetailBank, "bank"},
+	{RoomDetailPostOffice, "postoffice"},
+	{RoomDetailWilderness, "wilderness"},
+	{RoomDetailSubdivision, "subdivision"},
+}
+
+func (d RoomDetails) String() string {
+	var names []string
+	for _, detail := range roomDetailNames {
+		if d&detail.detail != 0 {
+			names = append(names, detail.name)
+		}
+	}
+
+	return strings.Join(names, ",")
+}
+
+func ParseRoomDetails(details []string) RoomDetails {
+	var out RoomDetails
+
+	for _, name := range details {
+		for _, detail := range roomDetailNames {
+			if strings.EqualFold(name, detail.name) {
+				out |= detail.detail
+			}
+		}
+	}
+
+	return out
+}
+
 type RoomWrongDirMessage struct {
 	BaseMessage
 
@@ -0,0 +1,191 @@
+package gmcp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+type OccupantChange int
+
+const (
+	// OccupantPresent is raised for players in the first Room.Players list after entering a
+	// room, who were already there when the list was sent
+	OccupantPresent OccupantChange = iota
+	OccupantArrived
+	OccupantDeparted
+)
+
+func (c OccupantChange) String() string {
+	switch c {
+	case OccupantPresent:
+		return "Present"
+	case OccupantArrived:
+		return "Arrived"
+	case OccupantDeparted:
+		return "Departed"
+	default:
+		return "Unknown"
+	}
+}
+
+type OccupantEvent struct {
+	Change OccupantChange
+	Room   int
+	Player CharName
+}
+
+func (e OccupantEvent) String() string {
+	name := e.Player.FullName
+	if name == "" {
+		name = e.Player.Name
+	}
+
+	return name + " " + strings.ToLower(e.Change.String())
+}
+
+type OccupantHook func(event OccupantEvent)
+
+type RoomOccupants struct {
+	lock    sync.Mutex
+	room    int
+	players []CharName
+	// listed is true once a Room.Players list has arrived for the current room
+	listed bool
+
+	hookLock sync.Mutex
+	hooks    []OccupantHook
+}
+
+func NewRoomOccupants() *RoomOccupants {
+	return &RoomOccupants{}
+}
+
+func (o *RoomOccupants) AddOccupantHook(hook OccupantHook) {
+	o.hookLock.Lock()
+	defer o.hookLock.Unlock()
+
+	o.hooks = append(o.hooks, hook)
+}
+
+func (o *RoomOccupants) Room() int {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.room
+}
+
+func (o *RoomOccupants) Players() []CharName {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return slices.Clone(o.players)
+}
+
+func (o *RoomOccupants) Present(name string) bool {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.indexOf(name) >= 0
+}
+
+// replacePlayers swaps in a new full player list and returns the occupant events it implies.
+// Must be called with lock held.
+func (o *RoomOccupants) replacePlayers(players []CharName) []OccupantEvent {
+	var events []OccupantEvent
+
+	if !o.listed {
+		// Players who arrived before the list did have already been reported
+		for _, player := range players {
+			if o.indexOf(player.Name) < 0 {
+				events = append(events, OccupantEvent{Change: OccupantPresent, Room: o.room, Player: player})
+			}
+		}
+	} else {
+		for _, player := range o.players {
+			if !containsPlayer(players, player.Name) {
+				events = append(events, OccupantEvent{Change: OccupantDeparted, Room: o.room, Player: player})
+			}
+		}
+
+		for _, player := range players {
+			if o.indexOf(player.Name) < 0 {
+				events = append(events, OccupantEvent{Change: OccupantArrived, Room: o.room, Player: player})
+			}
+		}
+	}
+
+	o.players = slices.Clone(players)
+	o.listed = true
+
+	return events
+}
+
+func containsPlayer(players []CharName, name string) bool {
+	return slices.ContainsFunc(players, func(player CharName) bool {
+		return strings.EqualFold(player.Name, name)
+	})
+}
+
+func (o *RoomOccupants) indexOf(name string) int {
+	return slices.IndexFunc(o.players, func(player CharName) bool {
+		return strings.EqualFold(player.Name, name)
+	})
+}
+
+// HandleEvent applies a Room message event to the occupant list and raises occupant
+// hooks for any players that were seen, arrived, or departed. Moving to another room
+// raises a departure for everyone in the old room. The first Room.Players list in a room
+// reports everyone as present, and later lists are compared against the players already
+// known, reporting the difference as arrivals and departures.
+func (o *RoomOccupants) HandleEvent(event telnet.TelOptEvent) {
+	o.lock.Lock()
+	var events []OccupantEvent
+
+	switch msg := event.(type) {
+	case RoomInfoMessage:
+		if msg.Number != o.room {
+			for _, player := range o.players {
+				events = append(events, OccupantEvent{Change: OccupantDeparted, Room: o.room, Player: player})
+			}
+
+			o.room = msg.Number
+			o.players = nil
+			o.listed = false
+		}
+	case RoomPlayersMessage:
+		events = o.replacePlayers(msg.Value)
+	case RoomAddPlayerMessage:
+		index := o.indexOf(msg.Name)
+		if index >= 0 {
+			o.players[index] = msg.CharName
+		} else {
+			o.players = append(o.players, msg.CharName)
+			events = append(events, OccupantEvent{Change: OccupantArrived, Room: o.room, Player: msg.CharName})
+		}
+	case RoomRemovePlayerMessage:
+		index := o.indexOf(msg.Value)
+		if index >= 0 {
+			player := o.players[index]
+			o.players = slices.Delete(o.players, index, index+1)
+			events = append(events, OccupantEvent{Change: OccupantDeparted, Room: o.room, Player: player})
+		}
+	}
+	o.lock.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	o.hookLock.Lock()
+	hooks := slices.Clone(o.hooks)
+	o.hookLock.Unlock()
+
+	for _, event := range events {
+		for _, hook := range hooks {
+			hook(event)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/moodclient/telnet"
+)
+
+func TestRoomOccupants(t *testing.T) {
+	occupants := NewRoomOccupants()
+
+	var got []string
+	occupants.AddOccupantHook(func(event OccupantEvent) {
+		got = append(got, event.String())
+	})
+
+	players := func(names ...string) RoomPlayersMessage {
+		msg := RoomPlayersMessage{}
+		for _, name := range names {
+			msg.Value = append(msg.Value, CharName{Name: name})
+		}
+		return msg
+	}
+	remove := func(name string) RoomRemovePlayerMessage {
+		msg := RoomRemovePlayerMessage{}
+		msg.Value = name
+		return msg
+	}
+
+	steps := []struct {
+		name     string
+		event    telnet.TelOptEvent
+		expected []string
+	}{
+		{"enter room", RoomInfoMessage{Number: 1}, nil},
+		// Someone arriving before the first list is reported once, not again as present
+		{"early arrival", RoomAddPlayerMessage{CharName: CharName{Name: "Tecton"}}, []string{"Tecton arrived"}},
+		{"first list", players("Tecton", "Bob", "Alice"), []string{"Bob present", "Alice present"}},
+		{"later list", players("bob", "Carol"), []string{"Tecton departed", "Alice departed", "Carol arrived"}},
+		{"same list", players("Bob", "Carol"), nil},
+		{"remove", remove("CAROL"), []string{"Carol departed"}},
+		{"remove unknown", remove("Carol"), nil},
+		{"same room", RoomInfoMessage{Number: 1}, nil},
+		{"change room", RoomInfoMessage{Number: 2}, []string{"Bob departed"}},
+		{"list in new room", players("Dave"), []string{"Dave present"}},
+	}
+
+	for _, step := range steps {
+		got = nil
+		occupants.HandleEvent(step.event)
+
+		if !slices.Equal(got, step.expected) {
+			t.Errorf("%s: expected %v, got %v", step.name, step.expected, got)
+		}
+	}
+
+	if occupants.Room() != 2 || !occupants.Present("dave") || occupants.Present("Bob") {
+		t.Errorf("unexpected final state: room %d, players %v", occupants.Room(), occupants.Players())
+	}
+}
@@ -0,0 +1,410 @@
+package gmcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/moodclient/telnet"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+	Sender               string                 `json:"x-gmcp-sender,omitempty"`
+}
+
+// TypeString returns a short human-readable description of the schema's type
+func (s *JSONSchema) TypeString() string {
+	if s == nil || s.Type == "" {
+		return "any"
+	}
+
+	switch s.Type {
+	case "array":
+		return s.Items.TypeString() + "[]"
+	case "object":
+		if s.AdditionalProperties != nil && len(s.Properties) == 0 {
+			return "map[string]" + s.AdditionalProperties.TypeString()
+		}
+	}
+
+	return s.Type
+}
+
+func (s *JSONSchema) isEmptyBody() bool {
+	return s.Type == "object" && len(s.Properties) == 0 && s.AdditionalProperties == nil
+}
+
+// Validate checks raw JSON against the schema
+func (s *JSONSchema) Validate(raw json.RawMessage) error {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		if s != nil && s.Type != "" && !s.isEmptyBody() {
+			return fmt.Errorf("schema: expected %s, got empty message", s.Type)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return err
+	}
+
+	return s.validateValue("$", value)
+}
+
+func (s *JSONSchema) validateValue(path string, value any) error {
+	if s == nil || s.Type == "" {
+		return nil
+	}
+
+	switch s.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("schema: %s: expected string", path)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("schema: %s: expected boolean", path)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("schema: %s: expected number", path)
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("schema: %s: expected integer", path)
+		}
+
+		if _, err := number.Int64(); err != nil {
+			return fmt.Errorf("schema: %s: expected integer, got %s", path, number)
+		}
+	case "array":
+		if value == nil {
+			// nil slices marshal as null
+			return nil
+		}
+
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("schema: %s: expected array", path)
+		}
+
+		for i, item := range items {
+			err := s.Items.validateValue(fmt.Sprintf("%s[%d]", path, i), item)
+			if err != nil {
+				return err
+			}
+		}
+	case "object":
+		if value == nil {
+			// nil maps marshal as null
+			return nil
+		}
+
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("schema: %s: expected object", path)
+		}
+
+		for _, required := range s.Required {
+			if _, exists := obj[required]; !exists {
+				return fmt.Errorf("schema: %s: missing required property %q", path, required)
+			}
+		}
+
+		for key, propValue := range obj {
+			propSchema, isProperty := s.Properties[key]
+			if !isProperty {
+				propSchema = s.AdditionalProperties
+			}
+
+			err := propSchema.validateValue(path+"."+key, propValue)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type MessageSchema struct {
+	ID     string
+	Sender telnet.TerminalSide
+	Schema *JSONSchema
+}
+
+type PackageSchema struct {
+	ID       string
+	Version  int
+	Messages []MessageSchema
+}
+
+func senderName(side telnet.TerminalSide) string {
+	if side == telnet.SideClient {
+		return "client"
+	}
+
+	return "server"
+}
+
+// Schema describes every message in the package. Each message's factory is called with a
+// nil GMCP and no raw message to find its ID, and its schema is derived from the Go type
+// the factory returns unless MessageData.Schema overrides it.
+func (p Package) Schema() (PackageSchema, error) {
+	out := PackageSchema{
+		ID:      p.ID,
+		Version: p.Version,
+	}
+
+	for message := range p.AllMessages {
+		msg, err := message.Create(nil, nil)
+		if err != nil {
+			return out, err
+		}
+
+		schema := message.Schema
+		if schema == nil {
+			schema = SchemaForType(reflect.TypeOf(msg))
+		}
+
+		out.Messages = append(out.Messages, MessageSchema{
+			ID:     msg.ID(),
+			Sender: message.Sender,
+			Schema: schema,
+		})
+	}
+
+	return out, nil
+}
+
+// JSONSchema builds a single JSON Schema document describing the package, with one
+// definition per message keyed as "<sender> <message ID>"
+func (s PackageSchema) JSONSchema() *JSONSchema {
+	doc := &JSONSchema{
+		Schema: jsonSchemaDraft,
+		ID:     "gmcp:" + s.ID,
+		Title:  fmt.Sprintf("%s %d", s.ID, s.Version),
+		Defs:   make(map[string]*JSONSchema, len(s.Messages)),
+	}
+
+	for _, message := range s.Messages {
+		def := *message.Schema
+		def.Title = message.ID
+		def.Sender = senderName(message.Sender)
+		doc.Defs[def.Sender+" "+message.ID] = &def
+	}
+
+	return doc
+}
+
+func (s PackageSchema) MarshalJSONSchema() ([]byte, error) {
+	return json.MarshalIndent(s.JSONSchema(), "", "  ")
+}
+
+func (s PackageSchema) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s (version %d)\n\n", s.ID, s.Version)
+
+	for _, message := range s.Messages {
+		fmt.Fprintf(&sb, "### %s\n\nSent by: %s\n\n", message.ID, senderName(message.Sender))
+
+		schema := message.Schema
+		switch {
+		case schema == nil || schema.isEmptyBody():
+			sb.WriteString("No body.\n\n")
+		case schema.Type == "object" && len(schema.Properties) > 0:
+			writeMarkdownProperties(&sb, schema)
+		default:
+			fmt.Fprintf(&sb, "Body: `%s`\n\n", schema.TypeString())
+			if schema.Items != nil && len(schema.Items.Properties) > 0 {
+				sb.WriteString("Elements:\n\n")
+				writeMarkdownProperties(&sb, schema.Items)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+func writeMarkdownProperties(sb *strings.Builder, schema *JSONSchema) {
+	sb.WriteString("| Field | Type | Required |\n|---|---|---|\n")
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		required := "no"
+		if slices.Contains(schema.Required, name) {
+			required = "yes"
+		}
+
+		fmt.Fprintf(sb, "| `%s` | `%s` | %s |\n", name, schema.Properties[name].TypeString(), required)
+	}
+
+	sb.WriteByte('\n')
+}
+
+// Schemas returns the schema for every registered package, sorted by package ID
+func (g *GMCP) Schemas() ([]PackageSchema, error) {
+	g.parseLock.Lock()
+	packages := make([]Package, 0, len(g.packages))
+	for _, pkg := range g.packages {
+		packages = append(packages, pkg)
+	}
+	g.parseLock.Unlock()
+
+	slices.SortFunc(packages, func(a, b Package) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	out := make([]PackageSchema, 0, len(packages))
+	for _, pkg := range packages {
+		schema, err := pkg.Schema()
+		if err != nil {
+			return nil, err
+		}
+
+		out = append(out, schema)
+	}
+
+	return out, nil
+}
+
+var (
+	mapMessageType     = reflect.TypeOf(MapMessage{})
+	itemAttributesType = reflect.TypeOf(ItemAttributes(0))
+	baseMessageType    = reflect.TypeOf(BaseMessage{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+func isValueMessage(t reflect.Type) bool {
+	return t.PkgPath() == mapMessageType.PkgPath() && strings.HasPrefix(t.Name(), "ValueMessage[")
+}
+
+// SchemaForType derives a JSON Schema from a Go type the same way encoding/json would
+// marshal it
+func SchemaForType(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == mapMessageType:
+		return &JSONSchema{Type: "object", AdditionalProperties: &JSONSchema{}}
+	case t == itemAttributesType:
+		return &JSONSchema{Type: "string", Description: "item attribute flags"}
+	case isValueMessage(t):
+		return SchemaForType(t.Field(0).Type)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: SchemaForType(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: SchemaForType(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+
+	return &JSONSchema{}
+}
+
+func structSchema(t reflect.Type) *JSONSchema {
+	// Messages that embed ValueMessage or MapMessage marshal as that value
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && (isValueMessage(field.Type) || field.Type == mapMessageType) {
+			return SchemaForType(field.Type)
+		}
+
+		// Embedded types that marshal themselves, such as IRETime, take over the message body
+		if field.Anonymous && field.Type != baseMessageType && field.Type.Kind() == reflect.Struct &&
+			field.Type.Implements(jsonMarshalerType) {
+			return SchemaForType(field.Type)
+		}
+	}
+
+	if t != baseMessageType && t.Implements(jsonMarshalerType) {
+		return &JSONSchema{}
+	}
+
+	schema := &JSONSchema{
+		Type:       "object",
+		Properties: make(map[string]*JSONSchema),
+	}
+	addStructProperties(schema, t)
+
+	return schema
+}
+
+func addStructProperties(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				addStructProperties(schema, fieldType)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = SchemaForType(field.Type)
+
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package gmcp
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ServerSession provides typed helpers for game code sending GMCP messages from the server.
+// Messages are only sent for packages the client supports. Between BeginTick and FlushTick,
+// messages are queued and state messages are coalesced, so that only one subnegotiation is
+// sent per piece of state between events. Messages are always sent in the order they were
+// first queued.
+type ServerSession struct {
+	g *GMCP
+
+	lock    sync.Mutex
+	inTick  bool
+	pending []pendingMessage
+}
+
+type pendingMessage struct {
+	msg Message
+	// onSent holds the callbacks of every message coalesced into this one, in the order they
+	// were queued. They run once the message has actually been written.
+	onSent []func()
+}
+
+func NewServerSession(g *GMCP) *ServerSession {
+	return &ServerSession{
+		g: g,
+	}
+}
+
+func (s *ServerSession) GMCP() *GMCP {
+	return s.g
+}
+
+func (s *ServerSession) Supports(packageID string) bool {
+	return s.g.ClientSupports(packageID)
+}
+
+func (s *ServerSession) BeginTick() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.inTick = true
+}
+
+// FlushTick sends all messages queued since BeginTick and stops queueing. An error sending
+// one message does not prevent the rest from being sent; all errors are joined and returned.
+func (s *ServerSession) FlushTick() error {
+	s.lock.Lock()
+	pending := coalesceMessages(s.pending)
+	s.pending = nil
+	s.inTick = false
+	s.lock.Unlock()
+
+	var errs []error
+	for _, queued := range pending {
+		err := s.write(queued.msg, queued.onSent)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *ServerSession) Send(packageID string, msg Message) error {
+	return s.sendTracked(packageID, msg, nil)
+}
+
+// sendTracked sends msg like Send, and calls onSent once msg has actually been written. If the
+// message is coalesced by its rate limit, onSent is called when it is flushed. It is never
+// called if the message is skipped, dropped or replaced by a newer message while waiting.
+func (s *ServerSession) sendTracked(packageID string, msg Message, onSent func()) error {
+	if !s.Supports(packageID) {
+		return nil
+	}
+
+	var callbacks []func()
+	if onSent != nil {
+		callbacks = []func(){onSent}
+	}
+
+	s.lock.Lock()
+	if s.inTick {
+		s.pending = append(s.pending, pendingMessage{msg: msg, onSent: callbacks})
+		s.lock.Unlock()
+		return nil
+	}
+	s.lock.Unlock()
+
+	return s.write(msg, callbacks)
+}
+
+func (s *ServerSession) write(msg Message, onSent []func()) error {
+	runCallbacks := func() {
+		for _, callback := range onSent {
+			callback()
+		}
+	}
+
+	result, err := s.g.trySendTracked(msg, runCallbacks)
+	if err != nil || result != SendResultSent {
+		return err
+	}
+
+	runCallbacks()
+	return nil
+}
+
+func (s *ServerSession) SendCharName(name string, fullName string) error {
+	return s.Send("Char", CharNameMessage{
+		CharName: CharName{Name: name, FullName: fullName},
+	})
+}
+
+func (s *ServerSession) SendVitals(values map[string]any) error {
+	msg := CharVitalsMessage{MapMessage: NewMapMessage("string")}
+	for key, value := range values {
+		msg.SetValue(key, value)
+	}
+
+	return s.Send("Char", msg)
+}
+
+func (s *ServerSession) SendStatus(values map[string]any) error {
+	msg := CharStatusMessage{MapMessage: NewMapMessage()}
+	for key, value := range values {
+		msg.SetValue(key, value)
+	}
+
+	return s.Send("Char", msg)
+}
+
+func (s *ServerSession) SendStatusVars(values map[string]any) error {
+	msg := CharStatusVarsMessage{MapMessage: NewMapMessage()}
+	for key, value := range values {
+		msg.SetValue(key, value)
+	}
+
+	return s.Send("Char", msg)
+}
+
+func (s *ServerSession) SendRoomInfo(info RoomInfoMessage) error {
+	return s.Send("Room", info)
+}
+
+func (s *ServerSession) SendRoomPlayers(players []CharName) error {
+	msg := RoomPlayersMessage{}
+	msg.Value = players
+	return s.Send("Room", msg)
+}
+
+func (s *ServerSession) SendRoomAddPlayer(player CharName) error {
+	return s.Send("Room", RoomAddPlayerMessage{CharName: player})
+}
+
+func (s *ServerSession) SendRoomRemovePlayer(name string) error {
+	msg := RoomRemovePlayerMessage{}
+	msg.Value = name
+	return s.Send("Room", msg)
+}
+
+func (s *ServerSession) SendItemsList(location string, items []Item) error {
+	return s.Send("Char.Items", CharItemsListMessage{
+		Location: location,
+		Items:    items,
+	})
+}
+
+func (s *ServerSession) SendItemsAdd(location string, item Item) error {
+	return s.Send("Char.Items", CharItemsAddMessage{
+		Location: location,
+		Item:     item,
+	})
+}
+
+func (s *ServerSession) SendItemsUpdate(location string, item Item) error {
+	return s.Send("Char.Items", CharItemsUpdateMessage{
+		Location: location,
+		Item:     item,
+	})
+}
+
+func (s *ServerSession) SendItemsRemove(location string, item Item) error {
+	return s.Send("Char.Items", CharItemsRemoveMessage{
+		Location: location,
+		Item:     item,
+	})
+}
+
+func (s *ServerSession) SendChannelList(channels []CommChannel) error {
+	msg := CommChannelListMessage{}
+	msg.Value = channels
+	return s.Send("Comm.Channel", msg)
+}
+
+func (s *ServerSession) SendChannelText(channel string, talker string, text string) error {
+	return s.Send("Comm.Channel", CommChannelTextMessage{
+		Channel: channel,
+		Talker:  talker,
+		Text:    text,
+	})
+}
+
+func (s *ServerSession) SendChannelPlayers(players []CommPlayer) error {
+	msg := CommChannelPlayersServerMessage{}
+	msg.Value = players
+	return s.Send("Comm.Channel", msg)
+}
+
+func (s *ServerSession) SendAfflictions(afflictions []Affliction) error {
+	msg := CharAfflictionsListMessage{}
+	msg.Value = afflictions
+	return s.Send("Char.Afflictions", msg)
+}
+
+func (s *ServerSession) SendDefences(defences []Defence) error {
+	msg := CharDefencesListMessage{}
+	msg.Value = defences
+	return s.Send("Char.Defences", msg)
+}
+
+func (s *ServerSession) SendClientGUI(version int, url string) error {
+	return s.Send("Client", ClientGUIMessage{
+		Version: FlexInt(version),
+		URL:     url,
+	})
+}
+
+func (s *ServerSession) SendClientMap(url string) error {
+	return s.Send("Client", ClientMapMessage{URL: url})
+}
+
+func (s *ServerSession) SendMediaDefault(url string) error {
+	return s.Send("Client.Media", ClientMediaDefaultMessage{URL: url})
+}
+
+func (s *ServerSession) SendMediaLoad(name string, url string) error {
+	msg := ClientMediaLoadMessage{Name: name, URL: url}
+	err := msg.Validate()
+	if err != nil {
+		return err
+	}
+
+	return s.Send("Client.Media", msg)
+}
+
+func (s *ServerSession) SendMediaPlay(msg ClientMediaPlayMessage) error {
+	err := msg.Validate()
+	if err != nil {
+		return err
+	}
+
+	return s.Send("Client.Media", msg)
+}
+
+func (s *ServerSession) SendMediaStop(msg ClientMediaStopMessage) error {
+	err := msg.Validate()
+	if err != nil {
+		return err
+	}
+
+	return s.Send("Client.Media", msg)
+}
+
+// coalesceKey returns the key under which later messages replace earlier ones within a tick,
+// or an empty string for event messages that must all be sent
+func coalesceKey(msg Message) string {
+	switch typed := msg.(type) {
+	case CharItemsListMessage:
+		return typed.ID() + " " + typed.Location
+	case CharNameMessage, CharVitalsMessage, CharStatusMessage, CharStatusVarsMessage,
+		RoomInfoMessage, RoomPlayersMessage, CommChannelListMessage, CommChannelPlayersServerMessage,
+		CharAfflictionsListMessage, CharDefencesListMessage:
+		return strings.ToUpper(typed.ID())
+	}
+
+	return ""
+}
+
+// mergeCoalesced combines two messages with the same coalesce key. Map-based messages are
+// merged key by key, since the earlier message may contain values the later one doesn't.
+func mergeCoalesced(earlier Message, later Message) Message {
+	switch typed := later.(type) {
+	case CharVitalsMessage:
+		merged := cloneMapMessage(earlier.(CharVitalsMessage).MapMessage)
+		mergeMapMessage(&merged, typed.MapMessage)
+		typed.MapMessage = merged
+		return typed
+	case CharStatusMessage:
+		merged := cloneMapMessage(earlier.(CharStatusMessage).MapMessage)
+		mergeMapMessage(&merged, typed.MapMessage)
+		typed.MapMessage = merged
+		return typed
+	case CharStatusVarsMessage:
+		merged := cloneMapMessage(earlier.(CharStatusVarsMessage).MapMessage)
+		mergeMapMessage(&merged, typed.MapMessage)
+		typed.MapMessage = merged
+		return typed
+	}
+
+	return later
+}
+
+func coalesceMessages(pending []pendingMessage) []pendingMessage {
+	out := make([]pendingMessage, 0, len(pending))
+	positions := make(map[string]int)
+
+	for _, queued := range pending {
+		key := coalesceKey(queued.msg)
+		if key == "" {
+			// State queued before an event is sent before it, so state is only coalesced
+			// between events and nothing is reordered
+			out = append(out, queued)
+			clear(positions)
+			continue
+		}
+
+		oldIndex, exists := positions[key]
+		if exists {
+			// Keep the position the state was first queued at
+			earlier := out[oldIndex]
+			out[oldIndex] = pendingMessage{
+				msg:    mergeCoalesced(earlier.msg, queued.msg),
+				onSent: append(slices.Clone(earlier.onSent), queued.onSent...),
+			}
+			continue
+		}
+
+		positions[key] = len(out)
+		out = append(out, queued)
+	}
+
+	return out
+}
@@ -0,0 +1,90 @@
+package gmcp
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+)
+
+func TestCoalesceMessagesKeepsOrder(t *testing.T) {
+	vitals := func(hp int) pendingMessage {
+		msg := CharVitalsMessage{MapMessage: NewMapMessage("string")}
+		msg.SetValue("hp", hp)
+		return pendingMessage{msg: msg}
+	}
+	text := func(text string) pendingMessage {
+		return pendingMessage{msg: CommChannelTextMessage{Channel: "say", Text: text}}
+	}
+
+	coalesced := coalesceMessages([]pendingMessage{
+		vitals(100),
+		vitals(90),
+		text("ouch"),
+		vitals(80),
+		text("ow"),
+		vitals(70),
+		vitals(60),
+	})
+
+	var got []any
+	for _, queued := range coalesced {
+		switch msg := queued.msg.(type) {
+		case CharVitalsMessage:
+			hp, _ := msg.Value("hp")
+			got = append(got, hp)
+		case CommChannelTextMessage:
+			got = append(got, msg.Text)
+		}
+	}
+
+	expected := []any{90, "ouch", 80, "ow", 60}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+type unmarshalableMessage struct {
+	BaseMessage
+}
+
+func (m unmarshalableMessage) ID() string {
+	return "Char.Broken"
+}
+
+func (m unmarshalableMessage) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("cannot marshal")
+}
+
+func TestFlushTickSendsAfterError(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	session := NewServerSession(server)
+	session.BeginTick()
+
+	for _, msg := range []Message{unmarshalableMessage{}, vitalsMessage(50), unmarshalableMessage{}} {
+		if err := session.Send("Char", msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := session.FlushTick()
+	if err == nil || strings.Count(err.Error(), "cannot marshal") != 2 {
+		t.Errorf("expected both errors to be returned, got %v", err)
+	}
+
+	if _, ok := telnettest.WaitForEventType(pair.Client, func(event CharVitalsMessage) bool {
+		return vitalsHP(event) == 50
+	}); !ok {
+		t.Error("vitals queued after a failed message were never sent")
+	}
+}
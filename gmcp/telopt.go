@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"unicode/utf8"
@@ -25,10 +27,11 @@ func RegisterGMCP(usage telnet.TelOptUsage, clientInfo mudopts.ClientInfo, packa
 		remoteClientSupported:    make(map[string]int),
 		remoteClientIntersection: make(map[string]struct{}),
 
-		clientMessages: make(map[string]MessageFactory),
-		serverMessages: make(map[string]MessageFactory),
+		clientMessages: newMessageRegistry(),
+		serverMessages: newMessageRegistry(),
 
-		messageToPackage: make(map[string]string),
+		sendBuckets: make(map[string]*sendBucket),
+		sendStats:   make(map[string]SendStats),
 	}
 
 	g.AddPackages(packages...)
@@ -44,13 +47,40 @@ type GMCP struct {
 	clientInfo mudopts.ClientInfo
 	packages   map[string]Package
 
+	// remoteClientSupported is keyed by package ID as the client advertised it, and
+	// remoteClientIntersection by the ID of the registered package each one matched
 	remoteClientSupported    map[string]int
 	remoteClientIntersection map[string]struct{}
 
-	clientMessages map[string]MessageFactory
-	serverMessages map[string]MessageFactory
+	clientMessages messageRegistry
+	serverMessages messageRegistry
 
-	messageToPackage map[string]string
+	parsePolicy ParsePolicy
+
+	sendBuckets map[string]*sendBucket
+	sendStats   map[string]SendStats
+
+	trafficLock       sync.Mutex
+	trafficHooks      []registeredTrafficHook
+	nextTrafficHookID int
+}
+
+// ParsePolicy returns how incoming messages that fail to parse, or that belong to no
+// registered package, are handled
+func (g *GMCP) ParsePolicy() ParsePolicy {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	return g.parsePolicy
+}
+
+// SetParsePolicy changes how incoming messages that fail to parse, or that belong to no
+// registered package, are handled. The default is ParsePolicyLenient.
+func (g *GMCP) SetParsePolicy(policy ParsePolicy) {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	g.parsePolicy = policy
 }
 
 func (g *GMCP) AddPackages(pkgs ...Package) error {
@@ -90,33 +120,22 @@ func (g *GMCP) AddPackages(pkgs ...Package) error {
 				return err
 			}
 
-			msgID := strings.ToUpper(msg.ID())
+			registered := registeredMessage{
+				packageID: pkg.ID,
+				create:    message.Create,
+			}
 
 			if message.Sender == telnet.SideClient {
-				g.clientMessages[msgID] = message.Create
+				g.clientMessages.add(msg.ID(), registered)
 			} else {
-				g.serverMessages[msgID] = message.Create
+				g.serverMessages.add(msg.ID(), registered)
 			}
-
-			g.messageToPackage[msgID] = pkg.ID
 		}
 	}
 
 	if g.Terminal() != nil && g.Terminal().Side() == telnet.SideServer {
 		// Update the intersection with client support
-		for _, removed := range removePackages {
-			clientVersion, clientSupports := g.remoteClientSupported[removed.ID]
-			if clientSupports && clientVersion == removed.Version {
-				delete(g.remoteClientIntersection, removed.ID)
-			}
-		}
-
-		for _, added := range addPackageSet {
-			clientVersion, clientSupports := g.remoteClientSupported[added.ID]
-			if clientSupports && clientVersion == added.Version {
-				g.remoteClientIntersection[added.ID] = struct{}{}
-			}
-		}
+		g.updateClientIntersection()
 	}
 
 	var err error
@@ -128,12 +147,12 @@ func (g *GMCP) AddPackages(pkgs ...Package) error {
 			// Set support
 			msg := CoreSupportsSetMessage{}
 			msg.Value = g.packageSupports(g.packages)
-			err = g.sendMessage(msg)
+			_, err = g.sendMessage(msg, nil)
 		} else {
 			// Add support
 			msg := CoreSupportsAddMessage{}
 			msg.Value = g.packageSupports(addPackageSet)
-			err = g.sendMessage(msg)
+			_, err = g.sendMessage(msg, nil)
 		}
 	}
 
@@ -156,9 +175,9 @@ func (g *GMCP) removePackages(pkgs map[string]Package) error {
 			}
 
 			if message.Sender == telnet.SideClient {
-				delete(g.clientMessages, msg.ID())
+				g.clientMessages.remove(msg.ID(), pkg.ID)
 			} else {
-				delete(g.serverMessages, msg.ID())
+				g.serverMessages.remove(msg.ID(), pkg.ID)
 			}
 		}
 	}
@@ -182,19 +201,14 @@ func (g *GMCP) RemovePackage(pkgs ...Package) error {
 
 	if g.Terminal() != nil && g.Terminal().Side() == telnet.SideServer {
 		// Update the intersection with client support
-		for _, removed := range pkgs {
-			clientVersion, clientSupports := g.remoteClientSupported[removed.ID]
-			if clientSupports && clientVersion == removed.Version {
-				delete(g.remoteClientIntersection, removed.ID)
-			}
-		}
+		g.updateClientIntersection()
 	}
 
 	if g.Terminal() != nil && g.Terminal().Side() == telnet.SideClient && g.RemoteState() == telnet.TelOptActive {
 		// Update support
 		msg := CoreSupportsRemoveMessage{}
 		msg.Value = g.packageSupports(pkgRemoveSet)
-		err = g.sendMessage(msg)
+		_, err = g.sendMessage(msg, nil)
 	}
 
 	return err
@@ -210,6 +224,132 @@ func (g *GMCP) packageSupports(pkgs map[string]Package) []string {
 	return out
 }
 
+// ClientSupports indicates whether the remote client has advertised support for a package
+// that is also registered locally. Core is always supported.
+func (g *GMCP) ClientSupports(packageID string) bool {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	return g.clientSupports(packageID)
+}
+
+// clientSupports checks package IDs the way message lookups do: an exact match first, and
+// otherwise the registered package that matches case-insensitively. IRE's Char and
+// Aardwolf's char are therefore separate packages when both are registered.
+func (g *GMCP) clientSupports(packageID string) bool {
+	if strings.EqualFold(packageID, "Core") {
+		return true
+	}
+
+	_, supported := g.remoteClientIntersection[packageID]
+	if supported {
+		return true
+	}
+
+	pkg, hasPackage := g.lookupPackage(packageID)
+	if !hasPackage {
+		return false
+	}
+
+	_, supported = g.remoteClientIntersection[pkg.ID]
+	return supported
+}
+
+// advertisedVersion returns the version of a registered package that the client advertised.
+// A package advertised in a different case only counts if no registered package has exactly
+// that ID.
+func (g *GMCP) advertisedVersion(packageID string) (int, bool) {
+	version, advertised := g.remoteClientSupported[packageID]
+	if advertised {
+		return version, true
+	}
+
+	for advertisedID, version := range g.remoteClientSupported {
+		if !strings.EqualFold(advertisedID, packageID) {
+			continue
+		}
+
+		if _, registered := g.packages[advertisedID]; !registered {
+			return version, true
+		}
+	}
+
+	return 0, false
+}
+
+// updateClientIntersection rebuilds the set of registered packages the client supports at
+// the same version
+func (g *GMCP) updateClientIntersection() {
+	clear(g.remoteClientIntersection)
+
+	for id, pkg := range g.packages {
+		version, advertised := g.advertisedVersion(id)
+		if advertised && version == pkg.Version {
+			g.remoteClientIntersection[id] = struct{}{}
+		}
+	}
+}
+
+// lookupPackage finds a registered package, preferring an exact match for id and otherwise
+// matching it case-insensitively
+func (g *GMCP) lookupPackage(id string) (Package, bool) {
+	pkg, exists := g.packages[id]
+	if exists {
+		return pkg, true
+	}
+
+	for registeredID, pkg := range g.packages {
+		if strings.EqualFold(registeredID, id) {
+			return pkg, true
+		}
+	}
+
+	return Package{}, false
+}
+
+func parsePackageKey(key string) (string, int) {
+	key = strings.TrimSpace(key)
+	id, versionStr, hasVersion := strings.Cut(key, " ")
+	if !hasVersion {
+		return id, 1
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(versionStr))
+	if err != nil {
+		return id, 1
+	}
+
+	return id, version
+}
+
+func (g *GMCP) updateClientSupport(msg Message) {
+	var keys []string
+
+	switch support := msg.(type) {
+	case CoreSupportsSetMessage:
+		clear(g.remoteClientSupported)
+		keys = support.Value
+	case CoreSupportsAddMessage:
+		keys = support.Value
+	case CoreSupportsRemoveMessage:
+		for _, key := range support.Value {
+			id, _ := parsePackageKey(key)
+			delete(g.remoteClientSupported, id)
+		}
+		g.updateClientIntersection()
+		return
+	default:
+		return
+	}
+
+	for _, key := range keys {
+		id, version := parsePackageKey(key)
+		g.remoteClientSupported[id] = version
+	}
+
+	g.updateClientIntersection()
+}
+
 func (g *GMCP) writeMessage(id string, rawJson []byte) error {
 	bytes := bytes.NewBuffer(make([]byte, 0, len(id)+len(rawJson)+1))
 	bytes.WriteString(id)
@@ -225,6 +365,8 @@ func (g *GMCP) writeMessage(id string, rawJson []byte) error {
 		Subnegotiation: bytes.Bytes(),
 	}, nil)
 
+	g.observeTraffic(TrafficOutgoing, id, redactLoginPassword(id, rawJson))
+
 	return nil
 }
 
@@ -232,32 +374,72 @@ func (g *GMCP) SendMessage(message Message) error {
 	g.parseLock.Lock()
 	defer g.parseLock.Unlock()
 
-	return g.sendMessage(message)
+	_, err := g.sendMessage(message, nil)
+	return err
 }
 
-func (g *GMCP) sendMessage(message Message) error {
-	if g.LocalState() != telnet.TelOptActive && g.RemoteState() != telnet.TelOptActive {
-		return nil
-	}
+// TrySendMessage sends a message like SendMessage, and also reports whether it was actually
+// written or was skipped, dropped or coalesced instead
+func (g *GMCP) TrySendMessage(message Message) (SendResult, error) {
+	return g.trySendTracked(message, nil)
+}
 
-	// The server shouldn't send messages we know the client doesn't support
-	if g.Terminal().Side() == telnet.SideServer {
-		pkgID, hasPkg := g.messageToPackage[message.ID()]
-		if hasPkg {
-			_, clientSupported := g.remoteClientIntersection[pkgID]
-			if !clientSupported {
-				return nil
-			}
-		}
+// trySendTracked sends a message like TrySendMessage. If the message is coalesced, onSent is
+// called once it is finally written, and never if a newer message replaces it first. onSent
+// is not called for any other result.
+func (g *GMCP) trySendTracked(message Message, onSent func()) (SendResult, error) {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	return g.sendMessage(message, onSent)
+}
+
+func (g *GMCP) sendMessage(message Message, onSent func()) (SendResult, error) {
+	if !g.canSend(message.ID()) {
+		return SendResultSkipped, nil
 	}
 
 	id := message.ID()
 	rawJson, err := json.Marshal(message)
 	if err != nil {
-		return err
+		return SendResultSkipped, err
+	}
+
+	return g.writeLimited(id, rawJson, onSent)
+}
+
+// SendRaw sends a message that has already been marshaled to JSON, applying the same
+// checks as SendMessage
+func (g *GMCP) SendRaw(id string, rawJson json.RawMessage) error {
+	g.parseLock.Lock()
+	defer g.parseLock.Unlock()
+
+	if !g.canSend(id) {
+		return nil
 	}
 
-	return g.writeMessage(id, rawJson)
+	_, err := g.writeLimited(id, rawJson, nil)
+	return err
+}
+
+func (g *GMCP) canSend(id string) bool {
+	if g.Terminal() == nil {
+		return false
+	}
+
+	if g.LocalState() != telnet.TelOptActive && g.RemoteState() != telnet.TelOptActive {
+		return false
+	}
+
+	// The server shouldn't send messages we know the client doesn't support
+	if g.Terminal().Side() == telnet.SideServer {
+		registered, hasPkg := g.serverMessages.lookup(id)
+		if hasPkg && !g.clientSupports(registered.packageID) {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (g *GMCP) TransitionRemoteState(newState telnet.TelOptState) (func() error, error) {
@@ -299,10 +481,8 @@ func (g *GMCP) TransitionLocalState(newState telnet.TelOptState) (func() error,
 		defer g.parseLock.Unlock()
 
 		// Clear client support
-		for key := range g.remoteClientSupported {
-			delete(g.remoteClientSupported, key)
-			delete(g.remoteClientIntersection, key)
-		}
+		clear(g.remoteClientSupported)
+		clear(g.remoteClientIntersection)
 
 		return nil, nil
 	}
@@ -329,33 +509,39 @@ func (g *GMCP) readMessageName(subnegotiation []byte) (string, int) {
 }
 
 func (g *GMCP) createMessage(messageName string, rawJson json.RawMessage) (Message, error) {
-	var factory MessageFactory
+	var registered registeredMessage
 	var hasFactory bool
 
 	if g.Terminal().Side() == telnet.SideClient {
 		// Sender was server
-		factory, hasFactory = g.serverMessages[strings.ToUpper(messageName)]
+		registered, hasFactory = g.serverMessages.lookup(messageName)
 	} else {
 		// Sender was client
-		factory, hasFactory = g.clientMessages[strings.ToUpper(messageName)]
+		registered, hasFactory = g.clientMessages.lookup(messageName)
+	}
+
+	if !hasFactory && g.parsePolicy != ParsePolicyLenient {
+		return nil, fmt.Errorf("gmcp: %w: %s", ErrUnknownMessage, messageName)
 	}
 
 	var err error
 	if !hasFactory {
+		// No package parses this message, so nothing needs the password
+		redactedJson := redactLoginPassword(messageName, rawJson)
 		msg := UnknownMessage{
 			id:         messageName,
-			rawMessage: rawJson,
+			rawMessage: redactedJson,
 			MapMessage: NewMapMessage(),
 		}
 
-		if len(rawJson) > 0 {
-			err = json.Unmarshal(rawJson, &msg)
+		if len(redactedJson) > 0 {
+			err = json.Unmarshal(redactedJson, &msg)
 		}
 
 		return msg, err
 	}
 
-	return factory(g, rawJson)
+	return registered.create(g, rawJson)
 }
 
 func (g *GMCP) Subnegotiate(subnegotiation []byte) error {
@@ -372,11 +558,29 @@ func (g *GMCP) Subnegotiate(subnegotiation []byte) error {
 		rawJson = append(rawJson, subnegotiation[consumed:]...)
 	}
 
-	g.parseLock.Lock()
-	defer g.parseLock.Unlock()
+	// Only the parsed message sees the real body- everything else gets the redacted copy
+	redactedJson := redactLoginPassword(messageName, rawJson)
+	g.observeTraffic(TrafficIncoming, messageName, redactedJson)
 
+	g.parseLock.Lock()
 	msg, err := g.createMessage(messageName, rawJson)
-	if err != nil {
+	policy := g.parsePolicy
+
+	if err == nil && g.Terminal().Side() == telnet.SideServer {
+		g.updateClientSupport(msg)
+	}
+	g.parseLock.Unlock()
+
+	// Events are raised outside the lock so that event handlers can send messages in response
+	if err != nil && policy == ParsePolicyQuarantine {
+		g.Terminal().RaiseTelOptEvent(MalformedMessage{
+			BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: g},
+			MessageID:       messageName,
+			Raw:             redactedJson,
+			Err:             err,
+		})
+		return nil
+	} else if err != nil {
 		return err
 	}
 
@@ -396,7 +600,7 @@ func (g *GMCP) SubnegotiationString(subnegotiation []byte) (string, error) {
 
 	if consumed < len(subnegotiation) {
 		sb.WriteByte(' ')
-		_, err := sb.Write(subnegotiation[consumed:])
+		_, err := sb.Write(redactLoginPassword(messageName, subnegotiation[consumed:]))
 		if err != nil {
 			return "", err
 		}
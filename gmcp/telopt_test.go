@@ -0,0 +1,261 @@
+package gmcp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/moodclient/mudopts"
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func startGMCPPair(t testing.TB, clientPackages []Package, serverPackages []Package) (*telnettest.Pair, *GMCP, *GMCP) {
+	t.Helper()
+
+	client := RegisterGMCP(telnet.TelOptAllowRemote, mudopts.ClientInfo{
+		Name:    "mudopts-test",
+		Version: "1.0",
+	}, clientPackages...)
+	server := RegisterGMCP(telnet.TelOptRequestLocal, mudopts.ClientInfo{}, serverPackages...)
+
+	pair := telnettest.Start(t, telnettest.PairConfig{
+		ClientOptions: []telnet.TelnetOption{client},
+		ServerOptions: []telnet.TelnetOption{server},
+	})
+
+	if !pair.Client.WaitForRemoteState(client, telnet.TelOptActive) {
+		t.Fatal("GMCP never became active")
+	}
+
+	return pair, client, server
+}
+
+func TestHelloAndSupports(t *testing.T) {
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()},
+		[]Package{NewPackageCore(), NewPackageChar(), NewPackageCommChannel()},
+	)
+
+	hello, ok := telnettest.WaitForEventType[CoreHelloMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("server never received Core.Hello")
+	}
+
+	if hello.Client != "mudopts-test" || hello.Version != "1.0" {
+		t.Errorf("unexpected hello: %+v", hello)
+	}
+
+	if _, ok := telnettest.WaitForEventType[CoreSupportsSetMessage](pair.Server, nil); !ok {
+		t.Fatal("server never received Core.Supports.Set")
+	}
+
+	tests := []struct {
+		packageID string
+		supported bool
+	}{
+		{"Core", true},
+		{"Char", true},
+		// The client supports Room but the server doesn't
+		{"Room", false},
+		// The server supports Comm.Channel but the client doesn't
+		{"Comm.Channel", false},
+	}
+
+	for _, test := range tests {
+		if server.ClientSupports(test.packageID) != test.supported {
+			t.Errorf("%s: expected supported=%t", test.packageID, test.supported)
+		}
+	}
+
+	// Package names are matched regardless of case
+	err := server.Subnegotiate([]byte(`Core.Supports.Add ["comm.channel 1"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.ClientSupports("Comm.Channel") || !server.ClientSupports("COMM.CHANNEL") {
+		t.Error("lower-case comm.channel support was not matched to Comm.Channel")
+	}
+}
+
+func TestPackagesChangeDuringSession(t *testing.T) {
+	pair, client, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar(), NewPackageRoom()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	err := client.AddPackages(NewPackageRoom())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := telnettest.WaitForEventType[CoreSupportsAddMessage](pair.Server, nil); !ok {
+		t.Fatal("server never received Core.Supports.Add")
+	}
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Room") }) {
+		t.Fatal("server never saw Room support")
+	}
+
+	err = server.SendMessage(RoomInfoMessage{Number: 12, Name: "A Test Room"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	room, ok := telnettest.WaitForEventType[RoomInfoMessage](pair.Client, nil)
+	if !ok {
+		t.Fatal("client never received Room.Info")
+	}
+
+	if room.Number != 12 || room.Name != "A Test Room" {
+		t.Errorf("unexpected room: %+v", room)
+	}
+
+	err = client.RemovePackage(NewPackageChar())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := telnettest.WaitForEventType[CoreSupportsRemoveMessage](pair.Server, nil); !ok {
+		t.Fatal("server never received Core.Supports.Remove")
+	}
+
+	if !pair.Server.Wait(func() bool { return !server.ClientSupports("Char") }) {
+		t.Fatal("server still believes the client supports Char")
+	}
+
+	if !server.ClientSupports("Room") {
+		t.Error("removing Char also removed Room support")
+	}
+}
+
+func FuzzSubnegotiate(f *testing.F) {
+	f.Add([]byte(`Core.Hello {"client":"mudlet","version":"4.17"}`))
+	f.Add([]byte(`Core.Supports.Set ["Char 1", "Room 1"]`))
+	f.Add([]byte(`Char.Vitals {"hp":"100","maxhp":"120"}`))
+	f.Add([]byte(`Char.Items.List {"location":"inv","items":[{"id":"1","name":"a sword","attrib":"wl"}]}`))
+	f.Add([]byte(`Room.Info {"num":1,"exits":{"n":2}}`))
+	f.Add([]byte(`Unknown.Message [1, 2, 3]`))
+	f.Add([]byte(`Core.Ping`))
+	f.Add([]byte(`Char.Vitals {`))
+	f.Add([]byte{0xff, 0xfe, ' ', '{'})
+
+	pair, _, server := startGMCPPair(f, []Package{NewPackageCore()}, NewIREPackages())
+
+	f.Fuzz(func(t *testing.T, subnegotiation []byte) {
+		// Errors are expected for malformed input, but nothing should panic
+		_ = server.Subnegotiate(subnegotiation)
+		pair.Server.ClearEvents()
+	})
+}
+
+func TestLoginPasswordRedacted(t *testing.T) {
+	// The server doesn't register Char.Login, so the message arrives as an UnknownMessage
+	pair, _, server := startGMCPPair(t, []Package{NewPackageCore()}, []Package{NewPackageCore()})
+
+	var recordLock sync.Mutex
+	var records []TrafficRecord
+	server.AddTrafficHook(func(record TrafficRecord) {
+		recordLock.Lock()
+		defer recordLock.Unlock()
+
+		records = append(records, record)
+	})
+
+	login := []byte(`Char.Login {"account":"bob","Password":"hunter2"}`)
+
+	debug, err := server.SubnegotiationString(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = server.Subnegotiate(login)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown, ok := telnettest.WaitForEventType[UnknownMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("server never raised Char.Login")
+	}
+
+	server.SetParsePolicy(ParsePolicyQuarantine)
+	err = server.Subnegotiate([]byte(`Char.Login {"account":"bob","password":"hunter2"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	malformed, ok := telnettest.WaitForEventType[MalformedMessage](pair.Server, nil)
+	if !ok {
+		t.Fatal("server never raised a malformed Char.Login")
+	}
+
+	outputs := map[string]string{
+		"SubnegotiationString": debug,
+		"UnknownMessage":       unknown.String(),
+		"UnknownMessage.Raw":   string(unknown.RawMessage()),
+		"MalformedMessage":     malformed.String(),
+		"MalformedMessage.Raw": string(malformed.Raw),
+	}
+
+	recordLock.Lock()
+	for i, record := range records {
+		outputs[fmt.Sprintf("TrafficRecord %d", i)] = record.Data
+	}
+	recordLock.Unlock()
+
+	for name, output := range outputs {
+		if strings.Contains(output, "hunter2") {
+			t.Errorf("%s leaked the password: %s", name, output)
+		}
+	}
+
+	if account, _ := unknown.MapMessage.String("account"); account != "bob" {
+		t.Errorf("redaction lost the account: %q", account)
+	}
+}
+
+func TestClientSupportsKeepsCaseDistinctPackages(t *testing.T) {
+	// IRE's Char and Aardwolf's char differ only in case
+	pair, _, server := startGMCPPair(t,
+		[]Package{NewPackageCore(), NewPackageChar()},
+		[]Package{NewPackageCore(), NewPackageChar(), NewPackageAardwolfChar()},
+	)
+
+	if !pair.Server.Wait(func() bool { return server.ClientSupports("Char") }) {
+		t.Fatal("server never saw Char support")
+	}
+
+	if server.ClientSupports("char") {
+		t.Error("advertising Char was treated as support for Aardwolf's char")
+	}
+
+	result, err := server.TrySendMessage(AardwolfCharVitalsMessage{})
+	if err != nil || result != SendResultSkipped {
+		t.Errorf("expected char.vitals to be skipped, got %s %v", result, err)
+	}
+
+	err = server.Subnegotiate([]byte(fmt.Sprintf(`Core.Supports.Add ["char %d"]`, NewPackageAardwolfChar().Version)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !server.ClientSupports("char") || !server.ClientSupports("Char") {
+		t.Error("advertising both packages didn't support both")
+	}
+
+	err = server.Subnegotiate([]byte(`Core.Supports.Remove ["Char"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.ClientSupports("Char") || !server.ClientSupports("char") {
+		t.Error("removing Char changed support for char")
+	}
+}
@@ -0,0 +1,299 @@
+package gmcp
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/moodclient/telnet"
+)
+
+const (
+	VitalHealth     = "health"
+	VitalMana       = "mana"
+	VitalEndurance  = "endurance"
+	VitalWillpower  = "willpower"
+	VitalMoves      = "moves"
+	VitalExperience = "experience"
+)
+
+type Gauge struct {
+	Name    string
+	Current int
+	Max     int
+}
+
+// Ratio returns Current as a fraction of Max, where 1 is full. It is 0 if Max is unknown.
+func (g Gauge) Ratio() float64 {
+	if g.Max <= 0 {
+		return 0
+	}
+
+	return float64(g.Current) / float64(g.Max)
+}
+
+type Vitals struct {
+	Gauges []Gauge
+}
+
+func (v Vitals) Gauge(name string) (Gauge, bool) {
+	for _, gauge := range v.Gauges {
+		if gauge.Name == name {
+			return gauge, true
+		}
+	}
+
+	return Gauge{}, false
+}
+
+func (v Vitals) clone() Vitals {
+	gauges := make([]Gauge, len(v.Gauges))
+	copy(gauges, v.Gauges)
+	return Vitals{Gauges: gauges}
+}
+
+func (v *Vitals) gauge(name string) *Gauge {
+	for i := range v.Gauges {
+		if v.Gauges[i].Name == name {
+			return &v.Gauges[i]
+		}
+	}
+
+	v.Gauges = append(v.Gauges, Gauge{Name: name})
+	return &v.Gauges[len(v.Gauges)-1]
+}
+
+func (v *Vitals) setCurrent(name string, value int) bool {
+	gauge := v.gauge(name)
+	changed := gauge.Current != value
+	gauge.Current = value
+	return changed
+}
+
+func (v *Vitals) setMax(name string, value int) bool {
+	gauge := v.gauge(name)
+	changed := gauge.Max != value
+	gauge.Max = value
+	return changed
+}
+
+type VitalsProfile interface {
+	Name() string
+	ApplyMessage(vitals *Vitals, message Message) bool
+}
+
+type IREVitalsProfile struct{}
+
+var _ VitalsProfile = IREVitalsProfile{}
+
+func (p IREVitalsProfile) Name() string {
+	return "IRE"
+}
+
+func (p IREVitalsProfile) ApplyMessage(vitals *Vitals, message Message) bool {
+	msg, isVitals := message.(CharVitalsMessage)
+	if !isVitals {
+		return false
+	}
+
+	var changed bool
+	pairs := []struct {
+		current string
+		max     string
+		gauge   string
+	}{
+		{"hp", "maxhp", VitalHealth},
+		{"mp", "maxmp", VitalMana},
+		{"ep", "maxep", VitalEndurance},
+		{"wp", "maxwp", VitalWillpower},
+	}
+
+	for _, pair := range pairs {
+		if current, ok := msg.Int(pair.current); ok {
+			changed = vitals.setCurrent(pair.gauge, current) || changed
+		}
+
+		if max, ok := msg.Int(pair.max); ok {
+			changed = vitals.setMax(pair.gauge, max) || changed
+		}
+	}
+
+	// nl is the percentage of the way to the next level
+	if nextLevel, ok := msg.Int("nl"); ok {
+		changed = vitals.setCurrent(VitalExperience, nextLevel) || changed
+		changed = vitals.setMax(VitalExperience, 100) || changed
+	}
+
+	return changed
+}
+
+type AardwolfVitalsProfile struct{}
+
+var _ VitalsProfile = AardwolfVitalsProfile{}
+
+func (p AardwolfVitalsProfile) Name() string {
+	return "Aardwolf"
+}
+
+func (p AardwolfVitalsProfile) ApplyMessage(vitals *Vitals, message Message) bool {
+	var changed bool
+
+	switch msg := message.(type) {
+	case AardwolfCharVitalsMessage:
+		changed = vitals.setCurrent(VitalHealth, int(msg.HP)) || changed
+		changed = vitals.setCurrent(VitalMana, int(msg.Mana)) || changed
+		changed = vitals.setCurrent(VitalMoves, int(msg.Moves)) || changed
+	case AardwolfCharMaxStatsMessage:
+		changed = vitals.setMax(VitalHealth, int(msg.MaxHP)) || changed
+		changed = vitals.setMax(VitalMana, int(msg.MaxMana)) || changed
+		changed = vitals.setMax(VitalMoves, int(msg.MaxMoves)) || changed
+
+	// Without the Aardwolf packages registered, char.vitals is parsed as Char.Vitals
+	// and char.maxstats is unknown
+	case CharVitalsMessage:
+		for _, gauge := range aardwolfGauges {
+			if current, ok := msg.Int(gauge.key); ok {
+				changed = vitals.setCurrent(gauge.name, current) || changed
+			}
+		}
+	case UnknownMessage:
+		if !strings.EqualFold(msg.ID(), "char.maxstats") {
+			return false
+		}
+
+		for _, gauge := range aardwolfGauges {
+			if max, ok := msg.Int("max" + gauge.key); ok {
+				changed = vitals.setMax(gauge.name, max) || changed
+			}
+		}
+	}
+
+	return changed
+}
+
+var aardwolfGauges = []struct {
+	key  string
+	name string
+}{
+	{"hp", VitalHealth},
+	{"mana", VitalMana},
+	{"moves", VitalMoves},
+}
+
+// GenericVitalsProfile treats every numeric key in Char.Vitals as a gauge, pairing
+// each "maxfoo" key with "foo". Common keys such as "hp" and "mana" are reported under the
+// matching Vital name, and any other key is used as its gauge's name. Keys are applied in
+// sorted order, so gauges always appear in the same order.
+type GenericVitalsProfile struct{}
+
+var _ VitalsProfile = GenericVitalsProfile{}
+
+var genericVitalNames = map[string]string{
+	"hp":         VitalHealth,
+	"health":     VitalHealth,
+	"mp":         VitalMana,
+	"mana":       VitalMana,
+	"sp":         VitalMana,
+	"ep":         VitalEndurance,
+	"endurance":  VitalEndurance,
+	"wp":         VitalWillpower,
+	"willpower":  VitalWillpower,
+	"mv":         VitalMoves,
+	"moves":      VitalMoves,
+	"xp":         VitalExperience,
+	"exp":        VitalExperience,
+	"experience": VitalExperience,
+}
+
+func genericVitalName(key string) string {
+	name, known := genericVitalNames[strings.ToLower(key)]
+	if known {
+		return name
+	}
+
+	return key
+}
+
+func (p GenericVitalsProfile) Name() string {
+	return "Generic"
+}
+
+func (p GenericVitalsProfile) ApplyMessage(vitals *Vitals, message Message) bool {
+	msg, isVitals := message.(CharVitalsMessage)
+	if !isVitals {
+		return false
+	}
+
+	var changed bool
+	for _, key := range slices.Sorted(msg.Keys) {
+		value, ok := msg.Int(key)
+		if !ok {
+			continue
+		}
+
+		name, isMax := strings.CutPrefix(key, "max")
+		if isMax && name != "" && msg.Has(name) {
+			changed = vitals.setMax(genericVitalName(name), value) || changed
+		} else {
+			changed = vitals.setCurrent(genericVitalName(key), value) || changed
+		}
+	}
+
+	return changed
+}
+
+type VitalsTracker struct {
+	lock    sync.Mutex
+	profile VitalsProfile
+	vitals  Vitals
+}
+
+func NewVitalsTracker(profile VitalsProfile) *VitalsTracker {
+	if profile == nil {
+		profile = GenericVitalsProfile{}
+	}
+
+	return &VitalsTracker{
+		profile: profile,
+	}
+}
+
+func (t *VitalsTracker) Profile() VitalsProfile {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.profile
+}
+
+func (t *VitalsTracker) SetProfile(profile VitalsProfile) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if profile == nil {
+		profile = GenericVitalsProfile{}
+	}
+
+	t.profile = profile
+	t.vitals = Vitals{}
+}
+
+func (t *VitalsTracker) Vitals() Vitals {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.vitals.clone()
+}
+
+// HandleEvent applies a GMCP message event to the tracked vitals and returns true
+// if any gauge changed
+func (t *VitalsTracker) HandleEvent(event telnet.TelOptEvent) bool {
+	message, isMessage := event.(Message)
+	if !isMessage {
+		return false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.profile.ApplyMessage(&t.vitals, message)
+}
@@ -0,0 +1,124 @@
+package gmcp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGenericVitalsProfile(t *testing.T) {
+	msg := CharVitalsMessage{MapMessage: NewMapMessage("string")}
+	msg.SetValue("hp", "250")
+	msg.SetValue("maxhp", 300)
+	msg.SetValue("mana", 40)
+	msg.SetValue("maxmana", "80")
+	msg.SetValue("rage", 3)
+	msg.SetValue("string", "H:250/300 M:40/80")
+
+	// Map iteration order is random, so apply the message several times to catch
+	// gauges that come out in a different order
+	for range 10 {
+		var vitals Vitals
+		if !(GenericVitalsProfile{}).ApplyMessage(&vitals, msg) {
+			t.Fatal("applying vitals reported no change")
+		}
+
+		expected := []Gauge{
+			{Name: VitalHealth, Current: 250, Max: 300},
+			{Name: VitalMana, Current: 40, Max: 80},
+			{Name: "rage", Current: 3},
+		}
+
+		if !slices.Equal(vitals.Gauges, expected) {
+			t.Fatalf("expected %+v, got %+v", expected, vitals.Gauges)
+		}
+	}
+}
+
+func TestIREVitalsProfile(t *testing.T) {
+	msg, err := CreateMessage[CharVitalsMessage](nil, []byte(`{"hp": "4500", "maxhp": "4800", "mp": "4000", "maxmp": "4000", "ep": "15000", "maxep": "15000", "wp": "12000", "maxwp": "12000", "nl": "10", "string": "H:4500/4800 M:4000/4000 E:15000/15000 W:12000/12000 NL:10/100 "}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vitals Vitals
+	if !(IREVitalsProfile{}).ApplyMessage(&vitals, msg) {
+		t.Fatal("applying vitals reported no change")
+	}
+
+	expected := []Gauge{
+		{Name: VitalHealth, Current: 4500, Max: 4800},
+		{Name: VitalMana, Current: 4000, Max: 4000},
+		{Name: VitalEndurance, Current: 15000, Max: 15000},
+		{Name: VitalWillpower, Current: 12000, Max: 12000},
+		{Name: VitalExperience, Current: 10, Max: 100},
+	}
+
+	if !slices.Equal(vitals.Gauges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, vitals.Gauges)
+	}
+
+	if (IREVitalsProfile{}).ApplyMessage(&vitals, msg) {
+		t.Error("applying the same vitals again reported a change")
+	}
+
+	if health, _ := vitals.Gauge(VitalHealth); health.Ratio() != 0.9375 {
+		t.Errorf("expected a health ratio of 0.9375, got %v", health.Ratio())
+	}
+
+	if (Gauge{Current: 5}).Ratio() != 0 {
+		t.Error("a gauge without a max should have a ratio of 0")
+	}
+}
+
+func TestAardwolfVitalsProfile(t *testing.T) {
+	vitalsMsg, err := CreateMessage[AardwolfCharVitalsMessage](nil, []byte(`{"hp": 4850, "mana": 3990, "moves": 2300}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maxStatsMsg, err := CreateMessage[AardwolfCharMaxStatsMessage](nil, []byte(`{"maxhp": 5000, "maxmana": 4000, "maxmoves": 2300, "maxstr": 25}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Gauge{
+		{Name: VitalHealth, Current: 4850, Max: 5000},
+		{Name: VitalMana, Current: 3990, Max: 4000},
+		{Name: VitalMoves, Current: 2300, Max: 2300},
+	}
+
+	var vitals Vitals
+	for _, msg := range []Message{vitalsMsg, maxStatsMsg} {
+		if !(AardwolfVitalsProfile{}).ApplyMessage(&vitals, msg) {
+			t.Fatalf("applying %s reported no change", msg.ID())
+		}
+	}
+
+	if !slices.Equal(vitals.Gauges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, vitals.Gauges)
+	}
+
+	// Without the Aardwolf packages, the same data arrives as Char.Vitals and an unknown
+	// char.maxstats
+	fallbackVitals, err := CreateMessage[CharVitalsMessage](nil, []byte(`{"hp": 4850, "mana": 3990, "moves": 2300}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fallbackMaxStats := UnknownMessage{id: "char.maxstats", MapMessage: NewMapMessage()}
+	err = fallbackMaxStats.UnmarshalJSON([]byte(`{"maxhp": 5000, "maxmana": 4000, "maxmoves": 2300}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fallback Vitals
+	for _, msg := range []Message{fallbackVitals, fallbackMaxStats} {
+		if !(AardwolfVitalsProfile{}).ApplyMessage(&fallback, msg) {
+			t.Fatalf("applying fallback %s reported no change", msg.ID())
+		}
+	}
+
+	if !slices.Equal(fallback.Gauges, expected) {
+		t.Errorf("fallback: expected %+v, got %+v", expected, fallback.Gauges)
+	}
+}
@@ -1,3 +1,12 @@
 module github.com/moodclient/mudopts
 
 go 1.23.0
+
+require github.com/moodclient/telnet v0.8.0
+
+require (
+	github.com/charmbracelet/x/ansi v0.5.2 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	golang.org/x/text v0.20.0 // indirect
+)
@@ -1,6 +1,10 @@
 package mudopts
 
 import (
+	"bufio"
+	"compress/zlib"
+	"errors"
+	"io"
 	"strings"
 
 	"github.com/moodclient/telnet/telopts"
@@ -31,3 +35,80 @@ func (s MCCPCompressionStatusEvent) String() string {
 
 	return sb.String()
 }
+
+// mccpCompressor flushes after every write so that the remote can decompress output as soon
+// as it is sent, rather than whenever zlib decides its buffer is full
+type mccpCompressor struct {
+	zlib *zlib.Writer
+}
+
+func newMCCPCompressor(writer io.Writer) (*mccpCompressor, error) {
+	compressor := &mccpCompressor{
+		zlib: zlib.NewWriter(writer),
+	}
+
+	// Send the zlib header right away so the remote isn't left waiting on it
+	return compressor, compressor.zlib.Flush()
+}
+
+func (c *mccpCompressor) Write(b []byte) (int, error) {
+	n, err := c.zlib.Write(b)
+	if err != nil {
+		return n, err
+	}
+
+	return n, c.zlib.Flush()
+}
+
+// Close ends the compressed stream. Anything written to the underlying writer afterward
+// is read uncompressed by the remote.
+func (c *mccpCompressor) Close() error {
+	return c.zlib.Close()
+}
+
+// mccpDecompressor reads a compressed stream and, once the remote ends it, continues reading
+// uncompressed data from the same buffer, so nothing sent after the end of the stream is lost
+type mccpDecompressor struct {
+	base     *bufio.Reader
+	zlib     io.ReadCloser
+	finished bool
+	onFinish func()
+}
+
+func newMCCPDecompressor(reader io.Reader, onFinish func()) *mccpDecompressor {
+	return &mccpDecompressor{
+		base:     bufio.NewReader(reader),
+		onFinish: onFinish,
+	}
+}
+
+func (d *mccpDecompressor) Read(b []byte) (int, error) {
+	if d.finished {
+		return d.base.Read(b)
+	}
+
+	if d.zlib == nil {
+		// zlib.NewReader blocks until the header arrives, so wait until the first read
+		// rather than blocking the subnegotiation that started compression
+		reader, err := zlib.NewReader(d.base)
+		if err != nil {
+			return 0, err
+		}
+		d.zlib = reader
+	}
+
+	n, err := d.zlib.Read(b)
+	if !errors.Is(err, io.EOF) {
+		return n, err
+	}
+
+	d.finished = true
+	d.zlib.Close()
+	d.onFinish()
+
+	if n > 0 {
+		return n, nil
+	}
+
+	return d.base.Read(b)
+}
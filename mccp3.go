@@ -1,7 +1,6 @@
 package mudopts
 
 import (
-	"compress/zlib"
 	"io"
 
 	"github.com/moodclient/telnet"
@@ -21,6 +20,7 @@ func RegisterMCCP3(usage telnet.TelOptUsage) *MCCP3 {
 type MCCP3 struct {
 	telopts.BaseTelOpt
 	compressed bool
+	compressor *mccpCompressor
 }
 
 func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error, error) {
@@ -37,7 +37,9 @@ func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error
 			}, func() error {
 				// Start sending compression
 				err := m.Terminal().Keyboard().WrapWriter(func(writer io.Writer) (io.Writer, error) {
-					return zlib.NewWriter(writer), nil
+					compressor, err := newMCCPCompressor(writer)
+					m.compressor = compressor
+					return compressor, err
 				})
 				if err != nil {
 					return err
@@ -46,7 +48,7 @@ func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error
 				// Send event
 				m.compressed = true
 				m.Terminal().RaiseTelOptEvent(MCCPCompressionStatusEvent{
-					BaseTelOptEvent: telopts.BaseTelOptEvent{m},
+					BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: m},
 					Started:         true,
 					Sending:         true,
 				})
@@ -56,10 +58,17 @@ func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error
 		}, nil
 	}
 
-	if newState == telnet.TelOptInactive {
+	if newState == telnet.TelOptInactive && m.compressor != nil {
 		return func() error {
+			// End the compressed stream, after which the remote will read uncompressed data
+			err := m.compressor.Close()
+			if err != nil {
+				return err
+			}
+			m.compressor = nil
+
 			// Stop sending compression
-			err := m.Terminal().Keyboard().WrapWriter(func(writer io.Writer) (io.Writer, error) {
+			err = m.Terminal().Keyboard().WrapWriter(func(writer io.Writer) (io.Writer, error) {
 				return writer, nil
 			})
 			if err != nil {
@@ -69,7 +78,7 @@ func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error
 			// Send event
 			m.compressed = false
 			m.Terminal().RaiseTelOptEvent(MCCPCompressionStatusEvent{
-				BaseTelOptEvent: telopts.BaseTelOptEvent{m},
+				BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: m},
 				Started:         false,
 				Sending:         true,
 			})
@@ -81,38 +90,12 @@ func (m *MCCP3) TransitionRemoteState(newState telnet.TelOptState) (func() error
 	return afterFunc, err
 }
 
-func (m *MCCP3) TransitionLocalState(newState telnet.TelOptState) (func() error, error) {
-	afterFunc, err := m.BaseTelOpt.TransitionLocalState(newState)
-	if err != nil {
-		return afterFunc, err
-	}
-
-	if newState == telnet.TelOptInactive && m.compressed {
-		// Stop receiving compression
-		err := m.Terminal().Printer().WrapReader(func(reader io.Reader) (io.Reader, error) {
-			return reader, nil
-		})
-		if err != nil {
-			return afterFunc, err
-		}
-
-		// Send event
-		m.compressed = false
-		m.Terminal().RaiseTelOptEvent(MCCPCompressionStatusEvent{
-			BaseTelOptEvent: telopts.BaseTelOptEvent{m},
-			Started:         false,
-			Sending:         false,
-		})
-	}
-
-	return afterFunc, err
-}
-
 func (m *MCCP3) Subnegotiate(subnegotiation []byte) error {
 	if m.LocalState() == telnet.TelOptActive {
-		// Start receiving compression
+		// Start receiving compression. The remote ends the compressed stream when it stops
+		// compressing, so there's no need to unwrap the reader when the option is turned off.
 		err := m.Terminal().Printer().WrapReader(func(reader io.Reader) (io.Reader, error) {
-			return zlib.NewReader(reader)
+			return newMCCPDecompressor(reader, m.stoppedReceiving), nil
 		})
 		if err != nil {
 			return err
@@ -121,7 +104,7 @@ func (m *MCCP3) Subnegotiate(subnegotiation []byte) error {
 		// Send event
 		m.compressed = true
 		m.Terminal().RaiseTelOptEvent(MCCPCompressionStatusEvent{
-			BaseTelOptEvent: telopts.BaseTelOptEvent{m},
+			BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: m},
 			Started:         true,
 			Sending:         false,
 		})
@@ -130,6 +113,15 @@ func (m *MCCP3) Subnegotiate(subnegotiation []byte) error {
 	return m.BaseTelOpt.Subnegotiate(subnegotiation)
 }
 
+func (m *MCCP3) stoppedReceiving() {
+	m.compressed = false
+	m.Terminal().RaiseTelOptEvent(MCCPCompressionStatusEvent{
+		BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: m},
+		Started:         false,
+		Sending:         false,
+	})
+}
+
 func (m *MCCP3) SubnegotiationString(subnegotiation []byte) (string, error) {
 	return "BEGIN COMPRESSION", nil
 }
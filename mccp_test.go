@@ -0,0 +1,112 @@
+package mudopts
+
+import (
+	"testing"
+
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func waitForCompression(t *testing.T, endpoint *telnettest.Endpoint, option telnet.TelnetOption, started bool, sending bool) {
+	t.Helper()
+
+	_, ok := telnettest.WaitForEventType[MCCPCompressionStatusEvent](endpoint, func(event MCCPCompressionStatusEvent) bool {
+		return event.Option() == option && event.Started == started && event.Sending == sending
+	})
+	if !ok {
+		t.Fatalf("no compression event with started=%t sending=%t", started, sending)
+	}
+}
+
+func TestMCCPStartAndStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(usage telnet.TelOptUsage) telnet.TelnetOption
+		code     telnet.TelOptCode
+		// serverSends is true when the server compresses its output, false when the client does
+		serverSends bool
+		// serverStops is true when the server sends WONT to end compression, false when the
+		// client sends DONT
+		serverStops bool
+	}{
+		{
+			name:        "MCCP2 stopped by server",
+			register:    func(usage telnet.TelOptUsage) telnet.TelnetOption { return RegisterMCCP2(usage) },
+			code:        mccp2,
+			serverSends: true,
+			serverStops: true,
+		},
+		{
+			name:        "MCCP2 stopped by client",
+			register:    func(usage telnet.TelOptUsage) telnet.TelnetOption { return RegisterMCCP2(usage) },
+			code:        mccp2,
+			serverSends: true,
+			serverStops: false,
+		},
+		{
+			name:        "MCCP3 stopped by server",
+			register:    func(usage telnet.TelOptUsage) telnet.TelnetOption { return RegisterMCCP3(usage) },
+			code:        mccp3,
+			serverSends: false,
+			serverStops: true,
+		},
+		{
+			name:        "MCCP3 stopped by client",
+			register:    func(usage telnet.TelOptUsage) telnet.TelnetOption { return RegisterMCCP3(usage) },
+			code:        mccp3,
+			serverSends: false,
+			serverStops: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := test.register(telnet.TelOptAllowRemote)
+			server := test.register(telnet.TelOptRequestLocal)
+
+			pair := telnettest.Start(t, telnettest.PairConfig{
+				ClientOptions: []telnet.TelnetOption{client},
+				ServerOptions: []telnet.TelnetOption{server},
+			})
+
+			sender, senderOption := pair.Client, client
+			receiver, receiverOption := pair.Server, server
+			if test.serverSends {
+				sender, senderOption = pair.Server, server
+				receiver, receiverOption = pair.Client, client
+			}
+
+			waitForCompression(t, sender, senderOption, true, true)
+			waitForCompression(t, receiver, receiverOption, true, false)
+
+			sender.SendText("compressed text\r\n")
+			if !receiver.WaitForText("compressed text") {
+				t.Fatalf("compressed text never arrived, received %q", receiver.Text())
+			}
+
+			if test.serverStops {
+				pair.Server.SendCommand(telnet.WONT, test.code)
+			} else {
+				pair.Client.SendCommand(telnet.DONT, test.code)
+			}
+
+			waitForCompression(t, sender, senderOption, false, true)
+			waitForCompression(t, receiver, receiverOption, false, false)
+
+			if !pair.Server.WaitForLocalState(server, telnet.TelOptInactive) {
+				t.Error("server still has the option active")
+			}
+
+			sender.SendText("uncompressed text\r\n")
+			if !receiver.WaitForText("uncompressed text") {
+				t.Errorf("text sent after compression stopped never arrived, received %q", receiver.Text())
+			}
+
+			for _, endpoint := range []*telnettest.Endpoint{pair.Client, pair.Server} {
+				if errs := endpoint.Errors(); len(errs) > 0 {
+					t.Errorf("terminal errors: %v", errs)
+				}
+			}
+		})
+	}
+}
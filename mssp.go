@@ -130,7 +130,7 @@ func (m *MSSP) Subnegotiate(subnegotiation []byte) error {
 
 	m.data.Store(&data)
 	m.Terminal().RaiseTelOptEvent(MSSPUpdatedEvent{
-		BaseTelOptEvent: telopts.BaseTelOptEvent{m},
+		BaseTelOptEvent: telopts.BaseTelOptEvent{TelnetOption: m},
 		Data:            data,
 	})
 
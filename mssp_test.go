@@ -0,0 +1,147 @@
+package mudopts
+
+import (
+	"bytes"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func mustParseURL(t *testing.T, rawURL string) *url.URL {
+	t.Helper()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed
+}
+
+// fullMSSPData sets every MSSPData field to a non-zero value
+func fullMSSPData(t *testing.T) MSSPData {
+	return MSSPData{
+		Name:    "Test MUD",
+		Players: 42,
+		Uptime:  time.Unix(1700000000, 0),
+
+		Charset:    []string{"ASCII", "UTF-8"},
+		Codebase:   []string{"Diku", "Merc"},
+		Contact:    "admin@example.com",
+		CrawlDelay: 12,
+		Created:    1999,
+		DiscordURL: mustParseURL(t, "https://discord.gg/example"),
+		Hostname:   "mud.example.com",
+		Icon:       mustParseURL(t, "https://example.com/icon.png"),
+		IP:         "192.0.2.1",
+		IPV6:       "2001:db8::1",
+		Language:   "English",
+		Location:   "United States",
+		MinimumAge: 13,
+		Port:       []int{4000, 4001},
+		Referral:   []string{"other.example.com:4000"},
+		SSLPort:    4443,
+		Website:    mustParseURL(t, "https://example.com"),
+
+		Family:     []string{"DikuMUD"},
+		Genre:      "Fantasy",
+		Gameplay:   "Hack and Slash",
+		Status:     "Live",
+		GameSystem: "Custom",
+		InterMUD:   []string{"IMC2", "I3"},
+		Subgenre:   "High Fantasy",
+
+		Areas:     10,
+		HelpFiles: 200,
+		Mobiles:   300,
+		Objects:   400,
+		Rooms:     5000,
+		Classes:   6,
+		Levels:    100,
+		Races:     8,
+		Skills:    150,
+
+		ANSI:           true,
+		UTF8:           true,
+		VT100:          true,
+		XTerm256Color:  true,
+		XTermTrueColor: true,
+
+		PayToPlay:   true,
+		PayForPerks: true,
+
+		HiringBuilders: true,
+		HiringCoders:   true,
+	}
+}
+
+func TestMSSPBufferRoundTrip(t *testing.T) {
+	data := fullMSSPData(t)
+
+	// Make sure a field added to MSSPData later isn't silently left out of the test
+	value := reflect.ValueOf(data)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).IsZero() {
+			t.Fatalf("fullMSSPData does not set %s", value.Type().Field(i).Name)
+		}
+	}
+
+	m := RegisterMSSP(0, MSSPData{})
+
+	var buffer bytes.Buffer
+	m.writeToBuffer(&buffer, data)
+
+	var read MSSPData
+	m.readFromBuffer(buffer.Bytes(), &read)
+
+	if !reflect.DeepEqual(data, read) {
+		t.Errorf("MSSP data did not round trip:\nwrote: %+v\n read: %+v", data, read)
+	}
+}
+
+func TestMSSPNegotiation(t *testing.T) {
+	data := fullMSSPData(t)
+
+	client := RegisterMSSP(telnet.TelOptAllowRemote, MSSPData{})
+	server := RegisterMSSP(telnet.TelOptRequestLocal, data)
+
+	pair := telnettest.Start(t, telnettest.PairConfig{
+		ClientOptions: []telnet.TelnetOption{client},
+		ServerOptions: []telnet.TelnetOption{server},
+	})
+
+	event, ok := telnettest.WaitForEventType[MSSPUpdatedEvent](pair.Client, nil)
+	if !ok {
+		t.Fatal("client never received MSSP data")
+	}
+
+	if !reflect.DeepEqual(data, event.Data) {
+		t.Errorf("MSSP data did not round trip:\n sent: %+v\n  got: %+v", data, event.Data)
+	}
+
+	if !reflect.DeepEqual(data, client.Data()) {
+		t.Error("client Data() does not match the received event")
+	}
+}
+
+func FuzzMSSPReadFromBuffer(f *testing.F) {
+	var buffer bytes.Buffer
+	m := RegisterMSSP(0, MSSPData{})
+	m.writeToBuffer(&buffer, MSSPData{Name: "Test MUD", Players: 3, Port: []int{4000, 4001}})
+	f.Add(buffer.Bytes())
+
+	f.Add([]byte{msspVAR, 'N', 'A', 'M', 'E', msspVAL})
+	f.Add([]byte{msspVAR, msspVAR, msspVAL, msspVAL})
+	f.Add([]byte("\x01PORT\x02-1\x02abc\x02\x01WEBSITE\x02%zz"))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		// Malformed data should be skipped, but nothing should panic or loop forever
+		var data MSSPData
+		m.readFromBuffer(b, &data)
+	})
+}
@@ -0,0 +1,331 @@
+// Package telnettest wires a client and server telnet.Terminal together in memory so that
+// telnet options can be negotiated and exercised in tests without a real socket.
+package telnettest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moodclient/telnet"
+)
+
+// DefaultTimeout is how long the Wait methods wait when no timeout is provided
+const DefaultTimeout = 2 * time.Second
+
+type PairConfig struct {
+	ClientOptions []telnet.TelnetOption
+	ServerOptions []telnet.TelnetOption
+
+	// Timeout overrides DefaultTimeout for the Wait methods
+	Timeout time.Duration
+}
+
+// Endpoint is one side of a Pair. It records every TelOptEvent, error and piece of printer
+// output its terminal raises.
+type Endpoint struct {
+	Terminal *telnet.Terminal
+	Conn     net.Conn
+
+	timeout time.Duration
+
+	lock    sync.Mutex
+	changed chan struct{}
+	events  []telnet.TelOptEvent
+	errors  []error
+	output  []telnet.TerminalData
+}
+
+func newEndpoint(conn net.Conn, timeout time.Duration) *Endpoint {
+	return &Endpoint{
+		Conn:    conn,
+		timeout: timeout,
+		changed: make(chan struct{}),
+	}
+}
+
+func (e *Endpoint) notify() {
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (e *Endpoint) recordEvent(_ *telnet.Terminal, event telnet.TelOptEvent) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.events = append(e.events, event)
+	e.notify()
+}
+
+func (e *Endpoint) recordError(_ *telnet.Terminal, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.errors = append(e.errors, err)
+	e.notify()
+}
+
+func (e *Endpoint) recordOutput(_ *telnet.Terminal, output telnet.TerminalData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.output = append(e.output, output)
+	e.notify()
+}
+
+// Events returns every event raised on this side so far
+func (e *Endpoint) Events() []telnet.TelOptEvent {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return slices.Clone(e.events)
+}
+
+// Errors returns every error this side's terminal has encountered so far
+func (e *Endpoint) Errors() []error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return slices.Clone(e.errors)
+}
+
+// Output returns everything this side's printer has received from the other side so far
+func (e *Endpoint) Output() []telnet.TerminalData {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return slices.Clone(e.output)
+}
+
+// Text returns all text this side's printer has received so far, without commands, control
+// codes or escape sequences
+func (e *Endpoint) Text() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var sb strings.Builder
+	for _, output := range e.output {
+		if text, isText := output.(telnet.TextData); isText {
+			sb.WriteString(string(text))
+		}
+	}
+
+	return sb.String()
+}
+
+// WaitForText waits until the text this side has received contains text
+func (e *Endpoint) WaitForText(text string) bool {
+	return e.Wait(func() bool {
+		return strings.Contains(e.Text(), text)
+	})
+}
+
+// ClearEvents forgets all recorded events, so later waits only see new ones
+func (e *Endpoint) ClearEvents() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.events = nil
+}
+
+// Wait blocks until condition returns true or the endpoint's timeout elapses. condition is
+// checked again every time an event or error is recorded, and at least every few milliseconds
+// for state that changes without raising an event.
+func (e *Endpoint) Wait(condition func() bool) bool {
+	deadline := time.NewTimer(e.timeout)
+	defer deadline.Stop()
+
+	for {
+		e.lock.Lock()
+		changed := e.changed
+		e.lock.Unlock()
+
+		if condition() {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-time.After(5 * time.Millisecond):
+		case <-deadline.C:
+			return condition()
+		}
+	}
+}
+
+// WaitForEvent returns the first recorded event matching match, waiting for one if necessary
+func (e *Endpoint) WaitForEvent(match func(event telnet.TelOptEvent) bool) (telnet.TelOptEvent, bool) {
+	var found telnet.TelOptEvent
+
+	ok := e.Wait(func() bool {
+		for _, event := range e.Events() {
+			if match(event) {
+				found = event
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return found, ok
+}
+
+// WaitForEventType returns the first recorded event of type T matching match, waiting for one
+// if necessary. A nil match accepts any event of type T.
+func WaitForEventType[T telnet.TelOptEvent](e *Endpoint, match func(event T) bool) (T, bool) {
+	event, ok := e.WaitForEvent(func(event telnet.TelOptEvent) bool {
+		typed, isType := event.(T)
+		return isType && (match == nil || match(typed))
+	})
+	if !ok {
+		var zero T
+		return zero, false
+	}
+
+	return event.(T), true
+}
+
+// WaitForLocalState waits until option is in state on this side
+func (e *Endpoint) WaitForLocalState(option telnet.TelnetOption, state telnet.TelOptState) bool {
+	return e.Wait(func() bool {
+		return option.LocalState() == state
+	})
+}
+
+// WaitForRemoteState waits until this side believes option is in state on the other side
+func (e *Endpoint) WaitForRemoteState(option telnet.TelnetOption, state telnet.TelOptState) bool {
+	return e.Wait(func() bool {
+		return option.RemoteState() == state
+	})
+}
+
+// SendCommand writes a raw telnet command from this side, such as WONT to turn off an option
+func (e *Endpoint) SendCommand(opCode byte, option telnet.TelOptCode) {
+	e.Terminal.Keyboard().WriteCommand(telnet.Command{
+		OpCode: opCode,
+		Option: option,
+	}, nil)
+}
+
+// SendText queues text to be sent to the other side. Unlike Keyboard().WriteString, it never
+// resends the last command written: telnet v0.8.0's WriteString doesn't reset its decoder.
+func (e *Endpoint) SendText(text string) {
+	e.Terminal.Keyboard().LineOut(e.Terminal, telnet.TextData(text))
+}
+
+// Pair is a client and server terminal connected over net.Pipe
+type Pair struct {
+	Client *Endpoint
+	Server *Endpoint
+
+	timeout   time.Duration
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewPair starts a client and a server terminal connected to one another, each with the
+// provided options registered. Negotiation begins immediately; use the Wait methods on each
+// Endpoint to synchronize with it.
+func NewPair(config PairConfig) (*Pair, error) {
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	clientConn, serverConn := net.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pair := &Pair{
+		Client:  newEndpoint(clientConn, timeout),
+		Server:  newEndpoint(serverConn, timeout),
+		timeout: timeout,
+		cancel:  cancel,
+	}
+
+	var err error
+	pair.Server.Terminal, err = newTerminal(ctx, pair.Server, telnet.SideServer, config.ServerOptions)
+	if err != nil {
+		pair.Close()
+		return nil, fmt.Errorf("telnettest: server terminal: %w", err)
+	}
+
+	pair.Client.Terminal, err = newTerminal(ctx, pair.Client, telnet.SideClient, config.ClientOptions)
+	if err != nil {
+		pair.Close()
+		return nil, fmt.Errorf("telnettest: client terminal: %w", err)
+	}
+
+	return pair, nil
+}
+
+func newTerminal(ctx context.Context, endpoint *Endpoint, side telnet.TerminalSide, options []telnet.TelnetOption) (*telnet.Terminal, error) {
+	return telnet.NewTerminal(ctx, endpoint.Conn, telnet.TerminalConfig{
+		DefaultCharsetName: "US-ASCII",
+		Side:               side,
+		TelOpts:            options,
+		EventHooks: telnet.EventHooks{
+			TelOptEvent:      []telnet.TelOptEventHandler{endpoint.recordEvent},
+			EncounteredError: []telnet.ErrorHandler{endpoint.recordError},
+			PrinterOutput:    []telnet.TerminalDataHandler{endpoint.recordOutput},
+		},
+	})
+}
+
+// Start creates a Pair and closes it when the test completes, failing the test if the pair
+// cannot be created
+func Start(t testing.TB, config PairConfig) *Pair {
+	t.Helper()
+
+	pair, err := NewPair(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		err := pair.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	return pair
+}
+
+// Close disconnects both terminals and waits up to the pair's timeout for them to shut down
+func (p *Pair) Close() error {
+	p.closeOnce.Do(func() {
+		// Cancelling first stops both printers. Closing the pipe afterward unblocks any keyboard
+		// that is still writing to a printer that has stopped reading.
+		p.cancel()
+		p.Client.Conn.Close()
+		p.Server.Conn.Close()
+
+		exited := make(chan struct{})
+		go func() {
+			defer close(exited)
+
+			for _, endpoint := range []*Endpoint{p.Client, p.Server} {
+				if endpoint.Terminal != nil {
+					// The error only reports that the pipe closed underneath the terminal
+					_ = endpoint.Terminal.WaitForExit()
+				}
+			}
+		}()
+
+		select {
+		case <-exited:
+		case <-time.After(p.timeout):
+			p.closeErr = errors.New("telnettest: terminals did not exit after the pair was closed")
+		}
+	})
+
+	return p.closeErr
+}
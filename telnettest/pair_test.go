@@ -0,0 +1,74 @@
+package telnettest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moodclient/mudopts"
+	"github.com/moodclient/mudopts/telnettest"
+	"github.com/moodclient/telnet"
+)
+
+func TestPairNegotiatesOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientUsage  telnet.TelOptUsage
+		serverUsage  telnet.TelOptUsage
+		expectActive bool
+	}{
+		{
+			name:         "server offers, client accepts",
+			clientUsage:  telnet.TelOptAllowRemote,
+			serverUsage:  telnet.TelOptRequestLocal,
+			expectActive: true,
+		},
+		{
+			name:         "server offers, client refuses",
+			clientUsage:  0,
+			serverUsage:  telnet.TelOptRequestLocal,
+			expectActive: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := mudopts.RegisterMSSP(test.clientUsage, mudopts.MSSPData{})
+			server := mudopts.RegisterMSSP(test.serverUsage, mudopts.MSSPData{Name: "Test MUD", Players: 3})
+
+			config := telnettest.PairConfig{
+				ClientOptions: []telnet.TelnetOption{client},
+				ServerOptions: []telnet.TelnetOption{server},
+			}
+			if !test.expectActive {
+				// Waiting for something that should never happen always takes the full timeout
+				config.Timeout = 250 * time.Millisecond
+			}
+
+			pair := telnettest.Start(t, config)
+
+			if !test.expectActive {
+				if pair.Server.WaitForLocalState(server, telnet.TelOptActive) {
+					t.Fatal("server MSSP became active without client permission")
+				}
+				return
+			}
+
+			if !pair.Client.WaitForRemoteState(client, telnet.TelOptActive) {
+				t.Fatal("client never saw MSSP become active")
+			}
+
+			event, ok := telnettest.WaitForEventType[mudopts.MSSPUpdatedEvent](pair.Client, nil)
+			if !ok {
+				t.Fatal("client never received MSSP data")
+			}
+
+			if event.Data.Name != "Test MUD" || event.Data.Players != 3 {
+				t.Errorf("unexpected MSSP data: %+v", event.Data)
+			}
+
+			if errs := pair.Client.Errors(); len(errs) > 0 {
+				t.Errorf("client errors: %v", errs)
+			}
+		})
+	}
+}